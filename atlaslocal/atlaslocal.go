@@ -12,11 +12,27 @@ import (
 	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// defaultImage is the Atlas Local image used when WithImage is not set.
+const defaultImage = "mongodb/mongodb-atlas-local:latest"
+
 // TeardownFunc is a function that tears down resources used during testing.
 type TeardownFunc func(t *testing.T)
 
 type options struct {
 	mongoClientOpts *mongooptions.ClientOptions
+	image           string
+	username        string
+	password        string
+
+	streamMongotLogs   bool
+	failureDiagnostics bool
+
+	loadSampleData bool
+	sampleDBs      []string
+
+	// extraContainerOpts holds testcontainers customizers contributed by
+	// options such as WithContainerEnv and WithEmbeddingServer.
+	extraContainerOpts []testcontainers.ContainerCustomizer
 }
 
 // NewAtlasLocalOption is a function that configures NewAtlasLocal.
@@ -29,17 +45,106 @@ func WithMongoClientOptions(opts *mongooptions.ClientOptions) Option {
 	}
 }
 
-// New creates a new MongoDB Atlas Local test container and returns a connected
-// mongo.Client and a TeardownFunc to clean up resources.
-func New(t *testing.T, ctx context.Context, optionFuncs ...Option) (*mongo.Client, TeardownFunc) {
+// WithImage configures the Atlas Local image, e.g.
+// "mongodb/mongodb-atlas-local:8.0". Defaults to the latest tag.
+func WithImage(image string) Option {
+	return func(o *options) {
+		o.image = image
+	}
+}
+
+// WithAuth starts Atlas Local with a root user (via the image's
+// MONGODB_INITDB_ROOT_USERNAME/PASSWORD env vars). The credentials are
+// embedded in the connection string used by the returned client, with
+// authSource=admin.
+func WithAuth(username, password string) Option {
+	return func(o *options) {
+		o.username = username
+		o.password = password
+	}
+}
+
+// WithContainerEnv sets additional environment variables on the Atlas Local
+// container.
+func WithContainerEnv(env map[string]string) Option {
+	return func(o *options) {
+		o.extraContainerOpts = append(o.extraContainerOpts, testcontainers.WithEnv(env))
+	}
+}
+
+// WithEmbeddingServer exposes the mock embedding server's host port to the
+// container and sets EmbeddingServer.ContainerEnv on it. Those variables are
+// a best guess, so this does not guarantee that mongot sends its embedding
+// requests to es; check EmbeddingServer.Requests.
+func WithEmbeddingServer(es *EmbeddingServer) Option {
+	return func(o *options) {
+		o.extraContainerOpts = append(o.extraContainerOpts,
+			testcontainers.WithHostPortAccess(es.port()),
+			testcontainers.WithEnv(es.ContainerEnv()))
+	}
+}
+
+// mongotPort is the port mongot listens on inside the Atlas Local container.
+const mongotPort = "27027/tcp"
+
+// Env provides access to the underlying Atlas Local deployment so additional
+// clients can connect to the same instance.
+type Env struct {
+	client     *mongo.Client
+	connString string
+	container  *atlaslocal.Container
+	mongotPort string
+}
+
+// ConnectionString returns the MongoDB connection URI, including credentials
+// when WithAuth is used.
+func (e *Env) ConnectionString() string {
+	return e.connString
+}
+
+// Container returns the underlying testcontainers Atlas Local container.
+func (e *Env) Container() *atlaslocal.Container {
+	return e.container
+}
+
+// MongotPort returns the host port mapped to mongot inside the container.
+func (e *Env) MongotPort() string {
+	return e.mongotPort
+}
+
+// newT starts the container and connects a client, returning the client,
+// a TeardownFunc, and the Env describing the deployment.
+func newT(t *testing.T, ctx context.Context, optionFuncs ...Option) (*mongo.Client, TeardownFunc, *Env) {
 	t.Helper()
 
-	opts := &options{}
+	opts := &options{image: defaultImage}
 	for _, apply := range optionFuncs {
 		apply(opts)
 	}
 
-	atlaslocalContainer, err := atlaslocal.Run(ctx, "mongodb/mongodb-atlas-local:latest")
+	containerOpts := []testcontainers.ContainerCustomizer{
+		testcontainers.WithExposedPorts(mongotPort),
+	}
+
+	// Always capture mongot's log so Env.MongotLogs can retrieve it; when
+	// streaming, route it to stderr where the log consumer picks it up.
+	if opts.streamMongotLogs {
+		containerOpts = append(containerOpts,
+			atlaslocal.WithMongotLogToStderr(),
+			testcontainers.WithLogConsumers(&mongotLogConsumer{t: t}))
+	} else {
+		containerOpts = append(containerOpts, atlaslocal.WithMongotLogFile())
+	}
+
+	if opts.username != "" || opts.password != "" {
+		containerOpts = append(containerOpts,
+			atlaslocal.WithUsername(opts.username),
+			atlaslocal.WithPassword(opts.password))
+	}
+
+	containerOpts = append(containerOpts, opts.extraContainerOpts...)
+
+	atlaslocalContainer, err := atlaslocal.Run(ctx, opts.image, containerOpts...)
 	require.NoError(t, err, "failed to start atlaslocal container")
 
 	tdFunc := func(t *testing.T) {
@@ -55,6 +160,21 @@ func New(t *testing.T, ctx context.Context, optionFuncs ...Option) (*mongo.Clien
 		t.Fatalf("failed to get connection string: %s", err)
 	}
 
+	mappedMongotPort, err := atlaslocalContainer.MappedPort(ctx, mongotPort)
+	if err != nil {
+		tdFunc(t)
+		t.Fatalf("failed to get mongot port: %s", err)
+	}
+
+	if opts.loadSampleData {
+		t.Logf("Loading sample data %v", opts.sampleDBs)
+
+		if err := loadSampleData(ctx, atlaslocalContainer, opts); err != nil {
+			tdFunc(t)
+			t.Fatalf("failed to load sample data: %s", err)
+		}
+	}
+
 	mopts := opts.mongoClientOpts
 	if mopts == nil {
 		mopts = mongooptions.Client()
@@ -69,10 +189,50 @@ func New(t *testing.T, ctx context.Context, optionFuncs ...Option) (*mongo.Clien
 		t.Fatalf("failed to connect to mongo: %s", err)
 	}
 
-	return mongoClient, func(t *testing.T) {
+	env := &Env{
+		client:     mongoClient,
+		connString: connString,
+		container:  atlaslocalContainer,
+		mongotPort: mappedMongotPort.Port(),
+	}
+
+	teardown := func(t *testing.T) {
 		t.Helper()
 
+		if opts.failureDiagnostics && t.Failed() {
+			dumpDiagnostics(t, ctx, mongoClient, env)
+		}
+
 		require.NoError(t, mongoClient.Disconnect(ctx), "failed to disconnect mongo client")
 		tdFunc(t)
 	}
+
+	readyCtx, readyCancel := context.WithTimeout(ctx, searchReadyTimeout)
+	defer readyCancel()
+
+	if err := env.WaitForSearchReady(readyCtx); err != nil {
+		teardown(t)
+		t.Fatalf("failed to wait for search readiness: %s", err)
+	}
+
+	return mongoClient, teardown, env
+}
+
+// New creates a new MongoDB Atlas Local test container and returns a connected
+// mongo.Client and a TeardownFunc to clean up resources.
+func New(t *testing.T, ctx context.Context, optionFuncs ...Option) (*mongo.Client, TeardownFunc) {
+	t.Helper()
+
+	client, teardown, _ := newT(t, ctx, optionFuncs...)
+
+	return client, teardown
+}
+
+// NewWithEnv creates a new MongoDB Atlas Local test container and returns a
+// connected mongo.Client, a TeardownFunc, and an Env for connecting
+// additional clients to the same deployment.
+func NewWithEnv(t *testing.T, ctx context.Context, optionFuncs ...Option) (*mongo.Client, TeardownFunc, *Env) {
+	t.Helper()
+
+	return newT(t, ctx, optionFuncs...)
 }
@@ -0,0 +1,99 @@
+package atlaslocal
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// systemDBs are skipped when dumping diagnostics.
+var systemDBs = map[string]bool{"admin": true, "config": true, "local": true}
+
+// WithFailureDiagnostics makes the teardown dump $listSearchIndexes output,
+// collection stats, and mongot logs to the test log when the test has
+// failed. Useful for "index never became queryable" failures.
+func WithFailureDiagnostics() Option {
+	return func(o *options) {
+		o.failureDiagnostics = true
+	}
+}
+
+// dumpDiagnostics logs the search index state of every user collection and
+// the mongot log. Errors are logged rather than failing the (already failed)
+// test.
+func dumpDiagnostics(t *testing.T, ctx context.Context, client *mongo.Client, env *Env) {
+	t.Helper()
+
+	t.Log("atlaslocal: test failed, dumping diagnostics")
+
+	dbNames, err := client.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		t.Logf("atlaslocal: failed to list databases: %v", err)
+	}
+
+	for _, dbName := range dbNames {
+		if systemDBs[dbName] {
+			continue
+		}
+
+		db := client.Database(dbName)
+
+		collNames, err := db.ListCollectionNames(ctx, bson.D{})
+		if err != nil {
+			t.Logf("atlaslocal: failed to list collections in %q: %v", dbName, err)
+			continue
+		}
+
+		for _, collName := range collNames {
+			dumpCollectionDiagnostics(t, ctx, db.Collection(collName))
+		}
+	}
+
+	logs, err := env.MongotLogs(ctx)
+	if err != nil {
+		t.Logf("atlaslocal: failed to read mongot logs: %v", err)
+		return
+	}
+
+	t.Logf("atlaslocal: mongot logs:\n%s", logs)
+}
+
+func dumpCollectionDiagnostics(t *testing.T, ctx context.Context, coll *mongo.Collection) {
+	t.Helper()
+
+	ns := coll.Database().Name() + "." + coll.Name()
+
+	statuses, err := listSearchIndexes(ctx, coll.SearchIndexes(), mongooptions.SearchIndexes())
+	if err != nil {
+		t.Logf("atlaslocal: %s: failed to list search indexes: %v", ns, err)
+	}
+
+	for _, status := range statuses {
+		t.Logf("atlaslocal: %s: search index: %s", ns, status.Raw)
+	}
+
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$collStats", Value: bson.D{
+			{Key: "count", Value: bson.D{}},
+			{Key: "storageStats", Value: bson.D{}},
+		}}},
+	})
+	if err != nil {
+		t.Logf("atlaslocal: %s: failed to get collection stats: %v", ns, err)
+		return
+	}
+
+	var stats []bson.Raw
+	if err := cursor.All(ctx, &stats); err != nil {
+		t.Logf("atlaslocal: %s: failed to decode collection stats: %v", ns, err)
+		return
+	}
+
+	for _, stat := range stats {
+		t.Logf("atlaslocal: %s: stats: %s", ns, stat)
+	}
+}
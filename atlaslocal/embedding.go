@@ -0,0 +1,212 @@
+package atlaslocal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// defaultEmbeddingDimensions matches the output dimension of the voyage-4
+// model family.
+const defaultEmbeddingDimensions = 1024
+
+// EmbeddingRequest is a single request received by an EmbeddingServer.
+type EmbeddingRequest struct {
+	Model     string
+	InputType string
+	Input     []string
+}
+
+// voyageRequest is the body of POST /v1/embeddings. Voyage accepts either a
+// single string or a list of strings for "input".
+type voyageRequest struct {
+	Input           json.RawMessage `json:"input"`
+	Model           string          `json:"model"`
+	InputType       string          `json:"input_type,omitempty"`
+	OutputDimension int             `json:"output_dimension,omitempty"`
+}
+
+type voyageEmbedding struct {
+	Object    string    `json:"object"`
+	Embedding []float64 `json:"embedding"`
+	Index     int       `json:"index"`
+}
+
+type voyageUsage struct {
+	TotalTokens int `json:"total_tokens"`
+}
+
+type voyageResponse struct {
+	Object string            `json:"object"`
+	Data   []voyageEmbedding `json:"data"`
+	Model  string            `json:"model"`
+	Usage  voyageUsage       `json:"usage"`
+}
+
+// EmbeddingServer is an in-process mock of the Voyage AI embeddings endpoint
+// (POST /v1/embeddings). It returns deterministic vectors derived from each
+// input text and records every request, so autoEmbed index tests can run
+// offline and assert which texts were sent for embedding.
+type EmbeddingServer struct {
+	srv        *httptest.Server
+	dimensions int
+
+	mu       sync.Mutex
+	requests []EmbeddingRequest
+}
+
+// NewEmbeddingServer starts a mock Voyage embeddings endpoint returning
+// vectors of the given dimension (voyage-4's 1024 if dimensions <= 0).
+func NewEmbeddingServer(t *testing.T, dimensions int) (*EmbeddingServer, TeardownFunc) {
+	t.Helper()
+
+	if dimensions <= 0 {
+		dimensions = defaultEmbeddingDimensions
+	}
+
+	es := &EmbeddingServer{dimensions: dimensions}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/embeddings", es.handleEmbeddings)
+
+	es.srv = httptest.NewServer(mux)
+
+	return es, func(t *testing.T) {
+		t.Helper()
+
+		es.srv.Close()
+	}
+}
+
+// URL returns the base URL of the server as reachable from the host.
+func (es *EmbeddingServer) URL() string {
+	return es.srv.URL
+}
+
+// ContainerURL returns the base URL of the server as reachable from inside a
+// container started with WithEmbeddingServer.
+func (es *EmbeddingServer) ContainerURL() string {
+	return fmt.Sprintf("http://%s:%d", testcontainers.HostInternal, es.port())
+}
+
+// ContainerEnv returns best-guess environment variables for pointing a
+// container's Voyage client at this server: a mock VOYAGE_API_KEY and
+// VOYAGE_API_BASE_URL. They are not documented inputs of the
+// mongodb-atlas-local image, and whether mongot reads them is unverified.
+// Pass the right variables through WithContainerEnv if it doesn't.
+func (es *EmbeddingServer) ContainerEnv() map[string]string {
+	return map[string]string{
+		"VOYAGE_API_KEY":      "mock-voyage-key",
+		"VOYAGE_API_BASE_URL": es.ContainerURL() + "/v1",
+	}
+}
+
+// Requests returns a copy of every embedding request received, in order.
+func (es *EmbeddingServer) Requests() []EmbeddingRequest {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	return append([]EmbeddingRequest(nil), es.requests...)
+}
+
+// Inputs returns every text sent for embedding across all requests, in order.
+func (es *EmbeddingServer) Inputs() []string {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	var inputs []string
+	for _, req := range es.requests {
+		inputs = append(inputs, req.Input...)
+	}
+
+	return inputs
+}
+
+func (es *EmbeddingServer) port() int {
+	_, portStr, _ := net.SplitHostPort(es.srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	return port
+}
+
+func (es *EmbeddingServer) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var req voyageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	inputs, err := decodeVoyageInput(req.Input)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	es.mu.Lock()
+	es.requests = append(es.requests, EmbeddingRequest{
+		Model:     req.Model,
+		InputType: req.InputType,
+		Input:     inputs,
+	})
+	es.mu.Unlock()
+
+	dims := es.dimensions
+	if req.OutputDimension > 0 {
+		dims = req.OutputDimension
+	}
+
+	resp := voyageResponse{Object: "list", Model: req.Model}
+	for i, input := range inputs {
+		resp.Data = append(resp.Data, voyageEmbedding{
+			Object:    "embedding",
+			Embedding: mockEmbedding(input, dims),
+			Index:     i,
+		})
+		resp.Usage.TotalTokens += len(input)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func decodeVoyageInput(raw json.RawMessage) ([]string, error) {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("input must be a string or list of strings: %w", err)
+	}
+
+	return list, nil
+}
+
+// mockEmbedding derives a deterministic vector in [-1, 1) from text, so the
+// same text always embeds to the same point.
+func mockEmbedding(text string, dims int) []float64 {
+	vec := make([]float64, dims)
+
+	var idx [8]byte
+	for i := range vec {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(text))
+
+		binary.LittleEndian.PutUint64(idx[:], uint64(i))
+		_, _ = h.Write(idx[:])
+
+		vec[i] = float64(h.Sum64())/float64(1<<63) - 1
+	}
+
+	return vec
+}
@@ -0,0 +1,47 @@
+package atlaslocal
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddingServer(t *testing.T) {
+	es, teardown := NewEmbeddingServer(t, 8)
+	defer teardown(t)
+
+	post := func(t *testing.T, body string) voyageResponse {
+		t.Helper()
+
+		resp, err := http.Post(es.URL()+"/v1/embeddings", "application/json", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got voyageResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+
+		return got
+	}
+
+	list := post(t, `{"input": ["alpha", "beta"], "model": "voyage-4", "input_type": "document"}`)
+	require.Len(t, list.Data, 2)
+	assert.Len(t, list.Data[0].Embedding, 8)
+	assert.NotEqual(t, list.Data[0].Embedding, list.Data[1].Embedding)
+
+	single := post(t, `{"input": "alpha", "model": "voyage-4", "input_type": "query"}`)
+	require.Len(t, single.Data, 1)
+	assert.Equal(t, list.Data[0].Embedding, single.Data[0].Embedding, "embeddings should be deterministic")
+
+	assert.Equal(t, []string{"alpha", "beta", "alpha"}, es.Inputs())
+
+	reqs := es.Requests()
+	require.Len(t, reqs, 2)
+	assert.Equal(t, "document", reqs[0].InputType)
+	assert.Equal(t, "query", reqs[1].InputType)
+}
@@ -0,0 +1,52 @@
+package atlaslocal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// WithMongotLogStream streams mongot's log to t.Log as the container runs.
+// mongot is configured to log to the container's stderr, so the stream (and
+// Env.MongotLogs) also includes anything else the image writes there.
+func WithMongotLogStream() Option {
+	return func(o *options) {
+		o.streamMongotLogs = true
+	}
+}
+
+// mongotLogConsumer forwards mongot's stderr output to the test log.
+type mongotLogConsumer struct {
+	t *testing.T
+}
+
+var _ testcontainers.LogConsumer = (*mongotLogConsumer)(nil)
+
+// Accept implements testcontainers.LogConsumer.
+func (c *mongotLogConsumer) Accept(l testcontainers.Log) {
+	if l.LogType != testcontainers.StderrLog {
+		return
+	}
+
+	c.t.Logf("mongot: %s", strings.TrimRight(string(l.Content), "\n"))
+}
+
+// MongotLogs returns the mongot process log from the Atlas Local container.
+func (e *Env) MongotLogs(ctx context.Context) (string, error) {
+	r, err := e.container.ReadMongotLogs(ctx)
+	if err != nil {
+		return "", fmt.Errorf("read mongot logs: %w", err)
+	}
+	defer r.Close()
+
+	logs, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("read mongot logs: %w", err)
+	}
+
+	return string(logs), nil
+}
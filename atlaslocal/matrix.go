@@ -0,0 +1,40 @@
+package atlaslocal
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// atlasLocalRepo is the Docker Hub repository for Atlas Local images.
+const atlasLocalRepo = "mongodb/mongodb-atlas-local"
+
+// MatrixFunc is the per-image callback run by RunMatrix.
+type MatrixFunc func(t *testing.T, client *mongo.Client, env *Env)
+
+// RunMatrix runs fn as a subtest against each of the given Atlas Local
+// images, starting a fresh deployment per image. Entries without a
+// repository (e.g. "8.0" or "latest") are treated as mongodb-atlas-local
+// tags. optionFuncs are applied to every deployment; WithImage is overridden.
+//
+// Asserting on feature availability inside fn (e.g. whether hnswOptions is
+// accepted) documents the differences between image versions.
+func RunMatrix(t *testing.T, images []string, fn MatrixFunc, optionFuncs ...Option) {
+	t.Helper()
+
+	for _, image := range images {
+		if !strings.Contains(image, "/") {
+			image = atlasLocalRepo + ":" + image
+		}
+
+		t.Run(image, func(t *testing.T) {
+			opts := append(append([]Option(nil), optionFuncs...), WithImage(image))
+
+			client, teardown, env := NewWithEnv(t, t.Context(), opts...)
+			defer teardown(t)
+
+			fn(t, client, env)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package atlaslocal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	searchReadyTimeout = 2 * time.Minute
+	searchProbeDB      = "atlaslocal_probe"
+	searchProbeColl    = "probe"
+
+	// errCodeNamespaceExists is returned when creating a collection that
+	// already exists.
+	errCodeNamespaceExists = 48
+)
+
+// WaitForSearchReady blocks until mongot accepts search index commands, by
+// polling $listSearchIndexes on a probe collection. mongod can report ready
+// before mongot has connected, in which case search index creation fails.
+// New calls this before returning, so it is only needed after restarting
+// mongot or when connecting additional clients early.
+func (e *Env) WaitForSearchReady(ctx context.Context) error {
+	db := e.client.Database(searchProbeDB)
+
+	if err := db.CreateCollection(ctx, searchProbeColl); err != nil {
+		var se mongo.ServerError
+		if !errors.As(err, &se) || !se.HasErrorCode(errCodeNamespaceExists) {
+			return fmt.Errorf("create search probe collection: %w", err)
+		}
+	}
+
+	view := db.Collection(searchProbeColl).SearchIndexes()
+
+	backoff := defaultAwaitInitialBackoff
+	for {
+		_, err := listSearchIndexes(ctx, view, mongooptions.SearchIndexes())
+		if err == nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("mongot not ready: %w (last error: %v)", ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, defaultAwaitMaxBackoff)
+	}
+
+	if err := db.Drop(ctx); err != nil {
+		return fmt.Errorf("drop search probe database: %w", err)
+	}
+
+	return nil
+}
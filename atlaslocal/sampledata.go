@@ -0,0 +1,118 @@
+package atlaslocal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb/atlaslocal"
+)
+
+const (
+	// sampleDataURL is the archive Atlas loads via "Load Sample Dataset".
+	sampleDataURL = "https://atlas-education.s3.amazonaws.com/sampledata.archive"
+
+	sampleDataContainerPath = "/tmp/sampledata.archive"
+)
+
+// WithSampleData loads the named Atlas sample datasets (e.g. "sample_mflix",
+// "sample_airbnb") into the deployment before the client is returned. With no
+// arguments, every sample dataset is loaded.
+//
+// The archive is downloaded once per machine and cached in os.TempDir, then
+// restored inside the container with mongorestore.
+func WithSampleData(dbs ...string) Option {
+	return func(o *options) {
+		o.loadSampleData = true
+		o.sampleDBs = append(o.sampleDBs, dbs...)
+	}
+}
+
+// loadSampleData restores the sample datasets into the container.
+func loadSampleData(ctx context.Context, c *atlaslocal.Container, opts *options) error {
+	archive, err := sampleDataArchive(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.CopyFileToContainer(ctx, archive, sampleDataContainerPath, 0o644); err != nil {
+		return fmt.Errorf("copy sample data archive: %w", err)
+	}
+
+	// mongorestore runs inside the container, so connect to mongod directly.
+	uri := &url.URL{Scheme: "mongodb", Host: "localhost:27017", Path: "/"}
+	query := url.Values{"directConnection": {"true"}}
+	if opts.username != "" {
+		uri.User = url.UserPassword(opts.username, opts.password)
+		query.Set("authSource", "admin")
+	}
+	uri.RawQuery = query.Encode()
+
+	cmd := []string{"mongorestore", "--uri", uri.String(), "--archive=" + sampleDataContainerPath, "--drop"}
+	for _, db := range opts.sampleDBs {
+		cmd = append(cmd, "--nsInclude", db+".*")
+	}
+
+	rc, out, err := c.Exec(ctx, cmd, exec.Multiplexed())
+	if err != nil {
+		return fmt.Errorf("run mongorestore: %w", err)
+	}
+
+	if rc != 0 {
+		output, _ := io.ReadAll(out)
+		return fmt.Errorf("mongorestore exited with %d: %s", rc, output)
+	}
+
+	return nil
+}
+
+// sampleDataArchive returns the path to a local copy of the sample data
+// archive, downloading it on first use.
+func sampleDataArchive(ctx context.Context) (string, error) {
+	path := filepath.Join(os.TempDir(), "atlaslocal-sampledata.archive")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sampleDataURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("build sample data request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download sample data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download sample data: unexpected status %s", resp.Status)
+	}
+
+	// Write to a temp file first so an interrupted download is never cached.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "atlaslocal-sampledata-*.partial")
+	if err != nil {
+		return "", fmt.Errorf("create sample data file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("write sample data: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("write sample data: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("cache sample data: %w", err)
+	}
+
+	return path, nil
+}
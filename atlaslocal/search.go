@@ -0,0 +1,267 @@
+package atlaslocal
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// SearchOperator is a $search operator such as text or compound.
+type SearchOperator interface {
+	// OperatorName is the key the operator is nested under in $search, e.g.
+	// "text".
+	OperatorName() string
+
+	// OperatorDocument is the operator's body.
+	OperatorDocument() bson.D
+}
+
+// SearchStage returns a $search aggregation stage that runs op against the
+// named search index. An empty index uses the server default ("default").
+func SearchStage(index string, op SearchOperator) bson.D {
+	search := bson.D{}
+	if index != "" {
+		search = append(search, bson.E{Key: "index", Value: index})
+	}
+
+	search = append(search, bson.E{Key: op.OperatorName(), Value: op.OperatorDocument()})
+
+	return bson.D{{Key: "$search", Value: search}}
+}
+
+// searchPath renders a path the way $search expects it: a bare string for a
+// single field, an array otherwise.
+func searchPath(path []string) any {
+	if len(path) == 1 {
+		return path[0]
+	}
+
+	return path
+}
+
+// TextOperator is the $search text operator.
+type TextOperator struct {
+	query    string
+	path     []string
+	maxEdits int
+}
+
+var _ SearchOperator = (*TextOperator)(nil)
+
+// Text creates a text operator matching query against the given paths.
+func Text(query string, path ...string) *TextOperator {
+	return &TextOperator{query: query, path: path}
+}
+
+// Fuzzy enables fuzzy matching with the given maximum number of single
+// character edits (1 or 2).
+func (op *TextOperator) Fuzzy(maxEdits int) *TextOperator {
+	op.maxEdits = maxEdits
+
+	return op
+}
+
+// OperatorName implements SearchOperator.
+func (op *TextOperator) OperatorName() string {
+	return "text"
+}
+
+// OperatorDocument implements SearchOperator.
+func (op *TextOperator) OperatorDocument() bson.D {
+	doc := bson.D{
+		{Key: "query", Value: op.query},
+		{Key: "path", Value: searchPath(op.path)},
+	}
+
+	if op.maxEdits > 0 {
+		doc = append(doc, bson.E{Key: "fuzzy", Value: bson.D{{Key: "maxEdits", Value: op.maxEdits}}})
+	}
+
+	return doc
+}
+
+// PhraseOperator is the $search phrase operator.
+type PhraseOperator struct {
+	query string
+	path  []string
+	slop  int
+}
+
+var _ SearchOperator = (*PhraseOperator)(nil)
+
+// Phrase creates a phrase operator matching the ordered terms of query
+// against the given paths.
+func Phrase(query string, path ...string) *PhraseOperator {
+	return &PhraseOperator{query: query, path: path}
+}
+
+// Slop sets the allowable distance between words in the phrase.
+func (op *PhraseOperator) Slop(slop int) *PhraseOperator {
+	op.slop = slop
+
+	return op
+}
+
+// OperatorName implements SearchOperator.
+func (op *PhraseOperator) OperatorName() string {
+	return "phrase"
+}
+
+// OperatorDocument implements SearchOperator.
+func (op *PhraseOperator) OperatorDocument() bson.D {
+	doc := bson.D{
+		{Key: "query", Value: op.query},
+		{Key: "path", Value: searchPath(op.path)},
+	}
+
+	if op.slop > 0 {
+		doc = append(doc, bson.E{Key: "slop", Value: op.slop})
+	}
+
+	return doc
+}
+
+// AutocompleteOperator is the $search autocomplete operator. The path must be
+// indexed with the autocomplete field type.
+type AutocompleteOperator struct {
+	query      string
+	path       string
+	tokenOrder string
+	maxEdits   int
+}
+
+var _ SearchOperator = (*AutocompleteOperator)(nil)
+
+// Autocomplete creates an autocomplete operator matching the prefix query
+// against path.
+func Autocomplete(query, path string) *AutocompleteOperator {
+	return &AutocompleteOperator{query: query, path: path}
+}
+
+// TokenOrder sets the token order, either "any" or "sequential".
+func (op *AutocompleteOperator) TokenOrder(order string) *AutocompleteOperator {
+	op.tokenOrder = order
+
+	return op
+}
+
+// Fuzzy enables fuzzy matching with the given maximum number of single
+// character edits (1 or 2).
+func (op *AutocompleteOperator) Fuzzy(maxEdits int) *AutocompleteOperator {
+	op.maxEdits = maxEdits
+
+	return op
+}
+
+// OperatorName implements SearchOperator.
+func (op *AutocompleteOperator) OperatorName() string {
+	return "autocomplete"
+}
+
+// OperatorDocument implements SearchOperator.
+func (op *AutocompleteOperator) OperatorDocument() bson.D {
+	doc := bson.D{
+		{Key: "query", Value: op.query},
+		{Key: "path", Value: op.path},
+	}
+
+	if op.tokenOrder != "" {
+		doc = append(doc, bson.E{Key: "tokenOrder", Value: op.tokenOrder})
+	}
+
+	if op.maxEdits > 0 {
+		doc = append(doc, bson.E{Key: "fuzzy", Value: bson.D{{Key: "maxEdits", Value: op.maxEdits}}})
+	}
+
+	return doc
+}
+
+// CompoundOperator is the $search compound operator.
+type CompoundOperator struct {
+	must               []SearchOperator
+	mustNot            []SearchOperator
+	should             []SearchOperator
+	filter             []SearchOperator
+	minimumShouldMatch int
+}
+
+var _ SearchOperator = (*CompoundOperator)(nil)
+
+// Compound creates an empty compound operator. Add clauses with Must,
+// MustNot, Should, and Filter.
+func Compound() *CompoundOperator {
+	return &CompoundOperator{}
+}
+
+// Must adds clauses that documents must match.
+func (op *CompoundOperator) Must(ops ...SearchOperator) *CompoundOperator {
+	op.must = append(op.must, ops...)
+
+	return op
+}
+
+// MustNot adds clauses that documents must not match.
+func (op *CompoundOperator) MustNot(ops ...SearchOperator) *CompoundOperator {
+	op.mustNot = append(op.mustNot, ops...)
+
+	return op
+}
+
+// Should adds clauses that increase the score of matching documents.
+func (op *CompoundOperator) Should(ops ...SearchOperator) *CompoundOperator {
+	op.should = append(op.should, ops...)
+
+	return op
+}
+
+// Filter adds clauses that documents must match without affecting score.
+func (op *CompoundOperator) Filter(ops ...SearchOperator) *CompoundOperator {
+	op.filter = append(op.filter, ops...)
+
+	return op
+}
+
+// MinimumShouldMatch sets how many should clauses must match.
+func (op *CompoundOperator) MinimumShouldMatch(n int) *CompoundOperator {
+	op.minimumShouldMatch = n
+
+	return op
+}
+
+// OperatorName implements SearchOperator.
+func (op *CompoundOperator) OperatorName() string {
+	return "compound"
+}
+
+// OperatorDocument implements SearchOperator.
+func (op *CompoundOperator) OperatorDocument() bson.D {
+	clauses := func(ops []SearchOperator) bson.A {
+		arr := make(bson.A, 0, len(ops))
+		for _, clause := range ops {
+			arr = append(arr, bson.D{{Key: clause.OperatorName(), Value: clause.OperatorDocument()}})
+		}
+
+		return arr
+	}
+
+	doc := bson.D{}
+	if len(op.must) > 0 {
+		doc = append(doc, bson.E{Key: "must", Value: clauses(op.must)})
+	}
+
+	if len(op.mustNot) > 0 {
+		doc = append(doc, bson.E{Key: "mustNot", Value: clauses(op.mustNot)})
+	}
+
+	if len(op.should) > 0 {
+		doc = append(doc, bson.E{Key: "should", Value: clauses(op.should)})
+	}
+
+	if len(op.filter) > 0 {
+		doc = append(doc, bson.E{Key: "filter", Value: clauses(op.filter)})
+	}
+
+	if op.minimumShouldMatch > 0 {
+		doc = append(doc, bson.E{Key: "minimumShouldMatch", Value: op.minimumShouldMatch})
+	}
+
+	return doc
+}
@@ -0,0 +1,62 @@
+package atlaslocal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestSearchStage(t *testing.T) {
+	tests := []struct {
+		name  string
+		index string
+		op    SearchOperator
+		want  string
+	}{
+		{
+			name:  "text single path",
+			index: "default",
+			op:    Text("baseball", "plot"),
+			want:  `{"$search": {"index": "default", "text": {"query": "baseball", "path": "plot"}}}`,
+		},
+		{
+			name: "text fuzzy multi path",
+			op:   Text("basebal", "plot", "title").Fuzzy(1),
+			want: `{"$search": {"text": {"query": "basebal", "path": ["plot", "title"], "fuzzy": {"maxEdits": {"$numberInt": "1"}}}}}`,
+		},
+		{
+			name: "phrase with slop",
+			op:   Phrase("new york", "title").Slop(2),
+			want: `{"$search": {"phrase": {"query": "new york", "path": "title", "slop": {"$numberInt": "2"}}}}`,
+		},
+		{
+			name: "autocomplete",
+			op:   Autocomplete("gre", "title").TokenOrder("sequential"),
+			want: `{"$search": {"autocomplete": {"query": "gre", "path": "title", "tokenOrder": "sequential"}}}`,
+		},
+		{
+			name: "compound",
+			op: Compound().
+				Must(Text("baseball", "plot")).
+				MustNot(Phrase("minor league", "plot")).
+				Should(Autocomplete("the", "title")).
+				MinimumShouldMatch(1),
+			want: `{"$search": {"compound": {` +
+				`"must": [{"text": {"query": "baseball", "path": "plot"}}], ` +
+				`"mustNot": [{"phrase": {"query": "minor league", "path": "plot"}}], ` +
+				`"should": [{"autocomplete": {"query": "the", "path": "title"}}], ` +
+				`"minimumShouldMatch": {"$numberInt": "1"}}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := bson.MarshalExtJSON(SearchStage(test.index, test.op), true, false)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package atlaslocal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	defaultAwaitTimeout        = 30 * time.Second
+	defaultAwaitInitialBackoff = 250 * time.Millisecond
+	defaultAwaitMaxBackoff     = 2 * time.Second
+)
+
+// SearchIndexStatus is the typed form of a single $listSearchIndexes result.
+type SearchIndexStatus struct {
+	ID               string   `bson:"id"`
+	Name             string   `bson:"name"`
+	Type             string   `bson:"type"`
+	Status           string   `bson:"status"`
+	Queryable        bool     `bson:"queryable"`
+	LatestDefinition bson.Raw `bson:"latestDefinition,omitempty"`
+
+	// Raw is the unmodified document returned by the server, for assertions
+	// on fields not modeled above.
+	Raw bson.Raw `bson:"-"`
+}
+
+// SearchIndexLister lists search indexes. mongo.SearchIndexView implements
+// it.
+type SearchIndexLister interface {
+	List(
+		ctx context.Context,
+		searchIdxOpts mongooptions.Lister[mongooptions.SearchIndexesOptions],
+		opts ...mongooptions.Lister[mongooptions.ListSearchIndexesOptions],
+	) (*mongo.Cursor, error)
+}
+
+type awaitOptions struct {
+	timeout          time.Duration
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	requireQueryable bool
+}
+
+// AwaitOption configures AwaitSearchIndex.
+type AwaitOption func(*awaitOptions)
+
+// WithAwaitTimeout bounds how long AwaitSearchIndex polls before giving up.
+// Defaults to 30s.
+func WithAwaitTimeout(timeout time.Duration) AwaitOption {
+	return func(o *awaitOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithAwaitBackoff sets the polling interval. The interval starts at initial
+// and doubles after each unsuccessful poll, capped at maxInterval or initial,
+// whichever is larger. Defaults to 250ms/2s; a non-positive value keeps its
+// default.
+func WithAwaitBackoff(initial, maxInterval time.Duration) AwaitOption {
+	return func(o *awaitOptions) {
+		if initial > 0 {
+			o.initialBackoff = initial
+		}
+
+		if maxInterval > 0 {
+			o.maxBackoff = maxInterval
+		}
+	}
+}
+
+// WithRequireQueryable makes AwaitSearchIndex wait until the index reports
+// queryable=true rather than returning as soon as it is listed. An index
+// whose build has FAILED never becomes queryable, so the wait stops there.
+func WithRequireQueryable() AwaitOption {
+	return func(o *awaitOptions) {
+		o.requireQueryable = true
+	}
+}
+
+// AwaitSearchIndex polls $listSearchIndexes until the named index is listed
+// (or queryable, see WithRequireQueryable) and returns its status. If the
+// timeout expires first, the last observed status (if any) is returned along
+// with an error wrapping context.DeadlineExceeded.
+func AwaitSearchIndex(
+	ctx context.Context,
+	view SearchIndexLister,
+	name string,
+	optionFuncs ...AwaitOption,
+) (*SearchIndexStatus, error) {
+	opts := &awaitOptions{
+		timeout:        defaultAwaitTimeout,
+		initialBackoff: defaultAwaitInitialBackoff,
+		maxBackoff:     defaultAwaitMaxBackoff,
+	}
+	for _, apply := range optionFuncs {
+		apply(opts)
+	}
+
+	awaitCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	var last *SearchIndexStatus
+
+	maxBackoff := max(opts.maxBackoff, opts.initialBackoff)
+
+	backoff := opts.initialBackoff
+	for {
+		status, err := lookupSearchIndex(awaitCtx, view, name)
+		if err != nil && awaitCtx.Err() == nil {
+			return nil, fmt.Errorf("list search index %q: %w", name, err)
+		}
+
+		if status != nil {
+			last = status
+			if !opts.requireQueryable || status.Queryable {
+				return status, nil
+			}
+
+			if status.Status == "FAILED" {
+				return status, fmt.Errorf("search index %q failed to build: %v", name, status.Raw)
+			}
+		}
+
+		select {
+		case <-awaitCtx.Done():
+			if last == nil {
+				return nil, fmt.Errorf("search index %q was never listed: %w", name, awaitCtx.Err())
+			}
+
+			return last, fmt.Errorf("search index %q not queryable (status %q): %w",
+				name, last.Status, awaitCtx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// AwaitAllIndexesQueryable polls $listSearchIndexes on coll until every
+// listed search index reports queryable=true, and returns the per-index
+// statuses. If the timeout expires first, the last observed statuses are
+// returned along with an error naming the indexes that are not queryable.
+func AwaitAllIndexesQueryable(
+	ctx context.Context,
+	coll *mongo.Collection,
+	timeout time.Duration,
+) ([]*SearchIndexStatus, error) {
+	awaitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	view := coll.SearchIndexes()
+
+	var last []*SearchIndexStatus
+
+	backoff := defaultAwaitInitialBackoff
+	for {
+		statuses, err := listSearchIndexes(awaitCtx, view, mongooptions.SearchIndexes())
+		if err != nil && awaitCtx.Err() == nil {
+			return nil, fmt.Errorf("list search indexes: %w", err)
+		}
+
+		if err == nil {
+			last = statuses
+
+			if len(notQueryable(statuses)) == 0 {
+				return statuses, nil
+			}
+		}
+
+		select {
+		case <-awaitCtx.Done():
+			return last, fmt.Errorf("search indexes %v not queryable: %w", notQueryable(last), awaitCtx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, defaultAwaitMaxBackoff)
+	}
+}
+
+// notQueryable returns the names of the indexes that are not yet queryable.
+func notQueryable(statuses []*SearchIndexStatus) []string {
+	var names []string
+	for _, status := range statuses {
+		if !status.Queryable {
+			names = append(names, status.Name)
+		}
+	}
+
+	return names
+}
+
+// lookupSearchIndex returns the status of the named search index, or nil if
+// the server does not list it yet.
+func lookupSearchIndex(ctx context.Context, view SearchIndexLister, name string) (*SearchIndexStatus, error) {
+	statuses, err := listSearchIndexes(ctx, view, mongooptions.SearchIndexes().SetName(name))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, status := range statuses {
+		if status.Name == name {
+			return status, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// listSearchIndexes returns the status of every search index matching sio.
+func listSearchIndexes(
+	ctx context.Context,
+	view SearchIndexLister,
+	sio *mongooptions.SearchIndexesOptionsBuilder,
+) ([]*SearchIndexStatus, error) {
+	cursor, err := view.List(ctx, sio)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var statuses []*SearchIndexStatus
+	for cursor.Next(ctx) {
+		status := &SearchIndexStatus{}
+		if err := cursor.Decode(status); err != nil {
+			return nil, fmt.Errorf("decode search index: %w", err)
+		}
+
+		status.Raw = append(bson.Raw(nil), cursor.Current...)
+		statuses = append(statuses, status)
+	}
+
+	return statuses, cursor.Err()
+}
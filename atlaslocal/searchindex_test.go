@@ -0,0 +1,45 @@
+package atlaslocal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// unlistedLister never lists an index, recording when it was called.
+type unlistedLister struct {
+	calls []time.Time
+}
+
+func (l *unlistedLister) List(
+	context.Context,
+	mongooptions.Lister[mongooptions.SearchIndexesOptions],
+	...mongooptions.Lister[mongooptions.ListSearchIndexesOptions],
+) (*mongo.Cursor, error) {
+	l.calls = append(l.calls, time.Now())
+
+	return mongo.NewCursorFromDocuments([]any{}, nil, bson.NewRegistry())
+}
+
+func TestAwaitSearchIndexBackoffCapBelowInitial(t *testing.T) {
+	const initial = 20 * time.Millisecond
+
+	lister := &unlistedLister{}
+
+	_, err := AwaitSearchIndex(context.Background(), lister, "default",
+		WithAwaitBackoff(initial, time.Millisecond), WithAwaitTimeout(150*time.Millisecond))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.GreaterOrEqual(t, len(lister.calls), 3)
+
+	// The cap is raised to the initial interval rather than shrinking it.
+	for i := 1; i < len(lister.calls); i++ {
+		assert.GreaterOrEqual(t, lister.calls[i].Sub(lister.calls[i-1]), initial, "gap before check %d", i)
+	}
+}
@@ -0,0 +1,168 @@
+package atlaslocal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// maxNumCandidates is the server-side upper bound on numCandidates.
+const maxNumCandidates = 10000
+
+// VectorSearchBuilder builds a $vectorSearch aggregation stage.
+type VectorSearchBuilder struct {
+	index         string
+	path          string
+	queryVector   []float64
+	numCandidates int
+	limit         int
+	filter        bson.D
+	exact         bool
+}
+
+// VectorSearchStage starts a $vectorSearch stage against the named index,
+// comparing queryVector with the vectors stored at path. Limit (and, unless
+// Exact is set, NumCandidates) must be set before Build.
+func VectorSearchStage(index, path string, queryVector []float64) *VectorSearchBuilder {
+	return &VectorSearchBuilder{index: index, path: path, queryVector: queryVector}
+}
+
+// NumCandidates sets the number of nearest neighbors considered during an
+// approximate (ANN) search.
+func (b *VectorSearchBuilder) NumCandidates(n int) *VectorSearchBuilder {
+	b.numCandidates = n
+
+	return b
+}
+
+// Limit sets the number of documents returned.
+func (b *VectorSearchBuilder) Limit(n int) *VectorSearchBuilder {
+	b.limit = n
+
+	return b
+}
+
+// Filter restricts the search to documents matching filter. Filtered fields
+// must be indexed with the filter type.
+func (b *VectorSearchBuilder) Filter(filter bson.D) *VectorSearchBuilder {
+	b.filter = filter
+
+	return b
+}
+
+// Exact requests an exact nearest neighbor (ENN) search, in which case
+// numCandidates must not be set.
+func (b *VectorSearchBuilder) Exact() *VectorSearchBuilder {
+	b.exact = true
+
+	return b
+}
+
+// Build validates the stage and returns it as BSON.
+func (b *VectorSearchBuilder) Build() (bson.D, error) {
+	var errs []error
+	if b.index == "" {
+		errs = append(errs, errors.New("index is required"))
+	}
+
+	if b.path == "" {
+		errs = append(errs, errors.New("path is required"))
+	}
+
+	if len(b.queryVector) == 0 {
+		errs = append(errs, errors.New("queryVector is required"))
+	}
+
+	if b.limit <= 0 {
+		errs = append(errs, fmt.Errorf("limit must be positive, got %d", b.limit))
+	}
+
+	switch {
+	case b.exact && b.numCandidates != 0:
+		errs = append(errs, errors.New("numCandidates cannot be set for an exact search"))
+	case !b.exact && b.numCandidates < b.limit:
+		errs = append(errs, fmt.Errorf("numCandidates (%d) must be >= limit (%d)", b.numCandidates, b.limit))
+	case !b.exact && b.numCandidates > maxNumCandidates:
+		errs = append(errs, fmt.Errorf("numCandidates (%d) must be <= %d", b.numCandidates, maxNumCandidates))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid $vectorSearch stage: %w", err)
+	}
+
+	vs := bson.D{
+		{Key: "index", Value: b.index},
+		{Key: "path", Value: b.path},
+		{Key: "queryVector", Value: b.queryVector},
+	}
+
+	if b.exact {
+		vs = append(vs, bson.E{Key: "exact", Value: true})
+	} else {
+		vs = append(vs, bson.E{Key: "numCandidates", Value: b.numCandidates})
+	}
+
+	vs = append(vs, bson.E{Key: "limit", Value: b.limit})
+
+	if b.filter != nil {
+		vs = append(vs, bson.E{Key: "filter", Value: b.filter})
+	}
+
+	return bson.D{{Key: "$vectorSearch", Value: vs}}, nil
+}
+
+// VectorSearchResult is a single document returned by RunVectorSearch.
+type VectorSearchResult struct {
+	ID       bson.RawValue
+	Score    float64
+	Document bson.Raw
+}
+
+// RunVectorSearch runs the $vectorSearch stage against coll, followed by any
+// additional stages, and returns each document with its vectorSearchScore.
+func RunVectorSearch(
+	ctx context.Context,
+	coll *mongo.Collection,
+	stage *VectorSearchBuilder,
+	stages ...bson.D,
+) ([]VectorSearchResult, error) {
+	vs, err := stage.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := mongo.Pipeline{vs}
+	pipeline = append(pipeline, stages...)
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.D{
+		{Key: "_id", Value: 0},
+		{Key: "doc", Value: "$$ROOT"},
+		{Key: "score", Value: bson.D{{Key: "$meta", Value: "vectorSearchScore"}}},
+	}}})
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("run $vectorSearch: %w", err)
+	}
+
+	var scored []struct {
+		Doc   bson.Raw `bson:"doc"`
+		Score float64  `bson:"score"`
+	}
+	if err := cursor.All(ctx, &scored); err != nil {
+		return nil, fmt.Errorf("decode $vectorSearch results: %w", err)
+	}
+
+	results := make([]VectorSearchResult, 0, len(scored))
+	for _, s := range scored {
+		results = append(results, VectorSearchResult{
+			ID:       s.Doc.Lookup("_id"),
+			Score:    s.Score,
+			Document: s.Doc,
+		})
+	}
+
+	return results, nil
+}
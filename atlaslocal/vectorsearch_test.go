@@ -0,0 +1,59 @@
+package atlaslocal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestVectorSearchStage(t *testing.T) {
+	t.Run("approximate", func(t *testing.T) {
+		stage, err := VectorSearchStage("vector_index", "embedding", []float64{0.5, -0.5}).
+			NumCandidates(100).
+			Limit(10).
+			Filter(bson.D{{Key: "year", Value: bson.D{{Key: "$gt", Value: 2000}}}}).
+			Build()
+		require.NoError(t, err)
+
+		got, err := bson.MarshalExtJSON(stage, false, false)
+		require.NoError(t, err)
+
+		want := `{"$vectorSearch": {"index": "vector_index", "path": "embedding", "queryVector": [0.5, -0.5], ` +
+			`"numCandidates": 100, "limit": 10, "filter": {"year": {"$gt": 2000}}}}`
+		assert.JSONEq(t, want, string(got))
+	})
+
+	t.Run("exact", func(t *testing.T) {
+		stage, err := VectorSearchStage("vector_index", "embedding", []float64{1}).Exact().Limit(1).Build()
+		require.NoError(t, err)
+
+		got, err := bson.MarshalExtJSON(stage, false, false)
+		require.NoError(t, err)
+
+		want := `{"$vectorSearch": {"index": "vector_index", "path": "embedding", "queryVector": [1.0], "exact": true, "limit": 1}}`
+		assert.JSONEq(t, want, string(got))
+	})
+
+	invalid := []struct {
+		name    string
+		builder *VectorSearchBuilder
+		errMsg  string
+	}{
+		{"missing index", VectorSearchStage("", "p", []float64{1}).NumCandidates(1).Limit(1), "index is required"},
+		{"missing path", VectorSearchStage("i", "", []float64{1}).NumCandidates(1).Limit(1), "path is required"},
+		{"missing vector", VectorSearchStage("i", "p", nil).NumCandidates(1).Limit(1), "queryVector is required"},
+		{"missing limit", VectorSearchStage("i", "p", []float64{1}).NumCandidates(1), "limit must be positive"},
+		{"candidates below limit", VectorSearchStage("i", "p", []float64{1}).NumCandidates(5).Limit(10), "must be >= limit"},
+		{"candidates above max", VectorSearchStage("i", "p", []float64{1}).NumCandidates(10001).Limit(10), "must be <= 10000"},
+		{"exact with candidates", VectorSearchStage("i", "p", []float64{1}).Exact().NumCandidates(5).Limit(1), "cannot be set for an exact search"},
+	}
+
+	for _, test := range invalid {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.builder.Build()
+			require.ErrorContains(t, err, test.errMsg)
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package det
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// WithBridgeNetwork runs the container on Docker's default bridge network
+// instead of the host network, so several deployments can run side by side
+// and the package works where host networking is unavailable (Docker Desktop
+// on macOS).
+//
+// The orchestration config (the DET default for the topology, or the one
+// given to WithOrchestrationFile) is rewritten so every member the client
+// talks to listens on a free host port, and each port is published 1:1. The
+// addresses members advertise to each other are therefore reachable from the
+// host as well. Not supported with WithLoadBalancer.
+func WithBridgeNetwork() Option {
+	return func(o *options) {
+		o.bridgeNetwork = true
+	}
+}
+
+// WithBasePort runs on the bridge network (see WithBridgeNetwork) with the
+// client-facing members on port, port+1, ... instead of free ports found
+// automatically. Either way, the ports are reserved until the test finishes,
+// so det deployments in parallel tests never collide.
+func WithBasePort(port int) Option {
+	return func(o *options) {
+		o.bridgeNetwork = true
+		o.basePort = port
+	}
+}
+
+// reservedPorts holds the host ports handed out to running deployments.
+var (
+	reservedPortsMu sync.Mutex
+	reservedPorts   = map[int]bool{}
+)
+
+// configureBridgeNetwork allocates host ports for the topology and writes the
+// rewritten orchestration config into dir, selecting it as the
+// orchestration file.
+func configureBridgeNetwork(cfg *options, dir string) error {
+	ports := topologyPorts(cfg.topology)
+
+	hostPorts, err := reservePorts(cfg.basePort, len(ports))
+	if err != nil {
+		return err
+	}
+
+	cfg.portMap = make(map[int]int, len(ports))
+	for i, port := range ports {
+		cfg.portMap[port] = hostPorts[i]
+	}
+
+	if err := writeBridgeOrchestrationFile(cfg, dir); err != nil {
+		releasePorts(cfg.portMap)
+		return err
+	}
+
+	return nil
+}
+
+// writeBridgeOrchestrationFile rewrites the orchestration config for the
+// ports in cfg.portMap into dir and selects it as the orchestration file.
+func writeBridgeOrchestrationFile(cfg *options, dir string) error {
+	base := cfg.orchestrationFile
+	if base == "" {
+		base = filepath.Join(cfg.detPath, ".evergreen", "orchestration", "configs",
+			orchestrationConfigDirs[cfg.topology], defaultOrchestrationFileName(cfg))
+	}
+
+	data, err := os.ReadFile(base)
+	if err != nil {
+		return fmt.Errorf("read orchestration config: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var config any
+	if err := dec.Decode(&config); err != nil {
+		return fmt.Errorf("decode orchestration config %s: %w", base, err)
+	}
+
+	rewritePorts(config, cfg.portMap)
+
+	out, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode orchestration config: %w", err)
+	}
+
+	dst := filepath.Join(dir, "bridge-"+filepath.Base(base))
+	if err := os.WriteFile(dst, out, 0o644); err != nil {
+		return fmt.Errorf("write orchestration config: %w", err)
+	}
+
+	cfg.orchestrationFile = dst
+
+	return nil
+}
+
+// defaultOrchestrationFileName mirrors how drivers_orchestration.py picks a
+// config when ORCHESTRATION_FILE is unset.
+func defaultOrchestrationFileName(cfg *options) string {
+	if cfg.ocspMode != "" {
+		return ocspOrchestrationFile(cfg)
+	}
+
+	name := "basic"
+	if cfg.authUser != "" {
+		name = "auth"
+	}
+
+	if cfg.ssl {
+		name += "-ssl"
+	}
+
+	switch {
+	case cfg.loadBalancer:
+		name += "-load-balancer"
+	case cfg.storageEngine != "":
+		name += "-" + cfg.storageEngine
+	}
+
+	return name + ".json"
+}
+
+// rewritePorts walks a decoded orchestration config and moves every process
+// listening on a port in portMap to its mapped port. Those processes must
+// also accept connections from outside the container's loopback interface.
+func rewritePorts(v any, portMap map[int]int) {
+	switch v := v.(type) {
+	case map[string]any:
+		if n, ok := v["port"].(json.Number); ok {
+			if port, err := n.Int64(); err == nil {
+				if mapped, ok := portMap[int(port)]; ok {
+					v["port"] = mapped
+					v["bind_ip"] = "0.0.0.0"
+				}
+			}
+		}
+
+		for _, child := range v {
+			rewritePorts(child, portMap)
+		}
+	case []any:
+		for _, child := range v {
+			rewritePorts(child, portMap)
+		}
+	}
+}
+
+// reservePorts reserves n host ports: basePort, basePort+1, ... if basePort
+// is positive, otherwise ports that are currently free.
+func reservePorts(basePort, n int) ([]int, error) {
+	reservedPortsMu.Lock()
+	defer reservedPortsMu.Unlock()
+
+	var ports []int
+	if basePort > 0 {
+		for i := range n {
+			port := basePort + i
+			if reservedPorts[port] {
+				return nil, fmt.Errorf("port %d is already used by another det deployment", port)
+			}
+
+			ports = append(ports, port)
+		}
+	} else {
+		var err error
+		if ports, err = freePorts(n); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, port := range ports {
+		reservedPorts[port] = true
+	}
+
+	return ports, nil
+}
+
+// releasePorts returns the host ports in portMap to the pool.
+func releasePorts(portMap map[int]int) {
+	reservedPortsMu.Lock()
+	defer reservedPortsMu.Unlock()
+
+	for _, port := range portMap {
+		delete(reservedPorts, port)
+	}
+}
+
+// freePorts returns n distinct TCP ports that are currently free on the host
+// and not reserved. The caller must hold reservedPortsMu.
+func freePorts(n int) ([]int, error) {
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}()
+
+	ports := make([]int, 0, n)
+	for len(ports) < n {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, fmt.Errorf("find free port: %w", err)
+		}
+
+		// Keep the listener open so the port isn't offered again.
+		listeners = append(listeners, l)
+
+		if port := l.Addr().(*net.TCPAddr).Port; !reservedPorts[port] {
+			ports = append(ports, port)
+		}
+	}
+
+	return ports, nil
+}
+
+// configureNetwork sets the container's network mode: host networking by
+// default, or the bridge network with each mapped port published 1:1.
+func configureNetwork(req *testcontainers.ContainerRequest, cfg *options) {
+	if !cfg.bridgeNetwork {
+		// Use host network mode so replica set members on 127.0.0.1 are accessible
+		req.NetworkMode = "host"
+		return
+	}
+
+	bindings := nat.PortMap{}
+	for _, hostPort := range cfg.portMap {
+		port := nat.Port(strconv.Itoa(hostPort) + "/tcp")
+
+		req.ExposedPorts = append(req.ExposedPorts, string(port))
+		bindings[port] = []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: strconv.Itoa(hostPort)}}
+	}
+
+	// The REST API isn't advertised anywhere, so any host port will do.
+	apiPort := nat.Port(strconv.Itoa(orchestrationPort) + "/tcp")
+	req.ExposedPorts = append(req.ExposedPorts, string(apiPort))
+	bindings[apiPort] = []nat.PortBinding{{HostIP: "0.0.0.0"}}
+
+	if cfg.socks5 {
+		proxyPort := nat.Port(strconv.Itoa(socks5Port) + "/tcp")
+		req.ExposedPorts = append(req.ExposedPorts, string(proxyPort))
+		bindings[proxyPort] = []nat.PortBinding{{HostIP: "0.0.0.0"}}
+	}
+
+	req.HostConfigModifier = func(hc *container.HostConfig) {
+		hc.PortBindings = bindings
+	}
+
+	// mongo-orchestration names members after HOSTNAME, which Docker sets to
+	// the container ID; members must advertise an address the host resolves.
+	req.Env["HOSTNAME"] = "localhost"
+}
@@ -0,0 +1,263 @@
+package det
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// imageRepo is the repository DET images are built into.
+const imageRepo = "det-mongodb"
+
+// WithSharedContainer reuses one deployment across every test in the binary
+// that asks for the same settings, instead of starting a container per test.
+// Teardown only disconnects the client; the container is reaped when the
+// test binary exits. Tests sharing a deployment must tolerate each other's
+// data. Not supported with WithBridgeNetwork.
+func WithSharedContainer() Option {
+	return func(o *options) {
+		o.sharedContainer = true
+	}
+}
+
+// imageRef returns the image for cfg, tagged by a hash of the build context,
+// Dockerfile path, MongoDB version, and topology so a build is only reused for
+// the settings and sources it was made for.
+func imageRef(cfg *options) (string, error) {
+	sum, err := contextHash(cfg.detPath)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s", sum, cfg.dockerfile, cfg.mongoDBVersion, cfg.topology)
+
+	return imageRepo + ":" + hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
+// contextHashes memoizes contextHash per DET path; the checkout isn't
+// expected to change while the test binary runs.
+var contextHashes sync.Map // string -> func() (string, error)
+
+// contextHash returns a hash of every file Docker would send as the build
+// context for dir: all of it except .git and what .dockerignore excludes.
+func contextHash(dir string) (string, error) {
+	f, _ := contextHashes.LoadOrStore(dir, sync.OnceValues(func() (string, error) {
+		return hashContext(dir)
+	}))
+
+	return f.(func() (string, error))()
+}
+
+func hashContext(dir string) (string, error) {
+	var patterns []string
+
+	if f, err := os.Open(filepath.Join(dir, ".dockerignore")); err == nil {
+		patterns, err = ignorefile.ReadAll(f)
+		f.Close()
+
+		if err != nil {
+			return "", fmt.Errorf("read .dockerignore: %w", err)
+		}
+	}
+
+	pm, err := patternmatcher.New(patterns)
+	if err != nil {
+		return "", fmt.Errorf("parse .dockerignore: %w", err)
+	}
+
+	h := sha256.New()
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		if d.IsDir() && rel == ".git" {
+			return filepath.SkipDir
+		}
+
+		ignored, err := pm.MatchesOrParentMatches(rel)
+		if err != nil {
+			return err
+		}
+
+		if ignored {
+			// A later "!" pattern may re-include something below an
+			// ignored directory, so only skip it when there are none.
+			if d.IsDir() && !pm.Exclusions() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(h, "%s\x00%s\x00", filepath.ToSlash(rel), info.Mode())
+
+		switch {
+		case info.Mode().IsRegular():
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			_, err = io.Copy(h, f)
+
+			return err
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			h.Write([]byte(target))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("hash build context %s: %w", dir, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// setImage points req at the cached image for cfg, or builds the DET
+// Dockerfile into it if there is none.
+func setImage(ctx context.Context, req *testcontainers.ContainerRequest, cfg *options) error {
+	image, err := imageRef(cfg)
+	if err != nil {
+		return err
+	}
+
+	if imageExists(ctx, image) {
+		req.Image = image
+		return nil
+	}
+
+	repo, tag, _ := strings.Cut(image, ":")
+	req.FromDockerfile = testcontainers.FromDockerfile{
+		Context:    cfg.detPath,
+		Dockerfile: cfg.dockerfile,
+		Repo:       repo,
+		Tag:        tag,
+		KeepImage:  true,
+	}
+
+	return nil
+}
+
+// imageExists reports whether image is available locally. Any error is
+// treated as a miss, falling back to building the image.
+func imageExists(ctx context.Context, image string) bool {
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return false
+	}
+	defer cli.Close()
+
+	_, err = cli.ImageInspect(ctx, image)
+
+	return err == nil
+}
+
+// sharedDeployments holds the deployments started with WithSharedContainer,
+// keyed by deploymentKey.
+var (
+	sharedMu          sync.Mutex
+	sharedDeployments = map[string]*sharedEntry{}
+)
+
+// sharedEntry is a shared deployment slot. mu is held while the deployment is
+// provisioned, so only tests asking for the same deployment wait on it.
+type sharedEntry struct {
+	mu  sync.Mutex
+	dep *deployment
+}
+
+// sharedDeployment returns the shared deployment for cfg, provisioning it on
+// first use. Tests asking for a deployment that is still being provisioned
+// wait for it; a failed provision is retried by the next test asking.
+func sharedDeployment(t *testing.T, ctx context.Context, cfg *options) (*deployment, error) {
+	t.Helper()
+
+	key, err := deploymentKey(cfg)
+	if err != nil {
+		return &deployment{}, err
+	}
+
+	sharedMu.Lock()
+
+	entry, ok := sharedDeployments[key]
+	if !ok {
+		entry = &sharedEntry{}
+		sharedDeployments[key] = entry
+	}
+
+	sharedMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.dep != nil {
+		return entry.dep, nil
+	}
+
+	dep, err := provision(t, ctx, cfg)
+	if err != nil {
+		return dep, err
+	}
+
+	entry.dep = dep
+
+	return dep, nil
+}
+
+// deploymentKey identifies the deployment cfg describes. Client options are
+// excluded since they don't affect the server.
+func deploymentKey(cfg *options) (string, error) {
+	image, err := imageRef(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%t\x00%t\x00%t\x00%t\x00%t",
+		image, cfg.detPath, cfg.downloadURL, cfg.storageEngine, cfg.authUser, cfg.authPassword,
+		cfg.orchestrationFile, cfg.ocspMode, cfg.ssl, cfg.loadBalancer, cfg.requireAPIVersion,
+		cfg.socks5, cfg.socks5Auth)
+
+	if cfg.orchestrationFile != "" {
+		config, err := os.ReadFile(cfg.orchestrationFile)
+		if err != nil {
+			return "", fmt.Errorf("read orchestration file: %w", err)
+		}
+
+		h.Write(config)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
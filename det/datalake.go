@@ -0,0 +1,90 @@
+package det
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// Credentials and container name used by DET's mongohoused test image.
+const (
+	DataLakeUser     = "mhuser"
+	DataLakePassword = "pencil"
+
+	dataLakeContainer = "mongohouse"
+)
+
+const dataLakeReadyTimeout = 2 * time.Minute
+
+// dataLakeDir holds DET's Atlas Data Lake scripts.
+const dataLakeDir = ".evergreen/atlas_data_lake"
+
+// WithDataLake brings up DET's mongohoused (Atlas Data Lake) test target
+// instead of a mongo-orchestration cluster, connecting as DataLakeUser. DET's
+// scripts pull the image from a private registry, so the test is skipped if
+// the pull fails (e.g. without the AWS credentials it needs). The target
+// listens on localhost:27017.
+func WithDataLake() Option {
+	return func(o *options) {
+		o.dataLake = true
+		o.authUser = DataLakeUser
+		o.authPassword = DataLakePassword
+	}
+}
+
+// pullDataLakeImage runs DET's pull script on the host, skipping the test if
+// the image can't be pulled.
+func pullDataLakeImage(t *testing.T, ctx context.Context, cfg *options) {
+	t.Helper()
+
+	if out, err := runDataLakeScript(ctx, cfg, "pull-mongohouse-image.sh"); err != nil {
+		t.Skipf("failed to pull mongohoused image: %s\n%s", err, out)
+	}
+}
+
+// startDataLake runs mongohoused through DET's run script and waits for it to
+// listen. The returned deployment stops it on teardown.
+func startDataLake(ctx context.Context, cfg *options) (*deployment, error) {
+	dep := &deployment{
+		connString: formatURI(cfg, hostAddr(cfg, 27017), url.Values{}),
+		stop:       stopDataLake,
+	}
+
+	if out, err := runDataLakeScript(ctx, cfg, "run-mongohouse-image.sh"); err != nil {
+		return dep, fmt.Errorf("run mongohoused: %w\n%s", err, out)
+	}
+
+	if err := awaitListening(ctx, hostAddr(cfg, 27017), dataLakeReadyTimeout); err != nil {
+		return dep, fmt.Errorf("wait for mongohoused: %w", err)
+	}
+
+	return dep, nil
+}
+
+// runDataLakeScript runs one of DET's Atlas Data Lake scripts on the host.
+// They drive the host's Docker daemon, so they can't run in a DET container.
+func runDataLakeScript(ctx context.Context, cfg *options, script string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "bash", filepath.Join(cfg.detPath, dataLakeDir, script))
+	cmd.Env = append(os.Environ(), "DRIVERS_TOOLS="+cfg.detPath)
+
+	return cmd.CombinedOutput()
+}
+
+// stopDataLake removes the mongohoused container started by DET's run script.
+func stopDataLake(ctx context.Context) error {
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	return cli.ContainerRemove(ctx, dataLakeContainer, container.RemoveOptions{Force: true})
+}
@@ -2,14 +2,21 @@ package det
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 
 	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -27,11 +34,48 @@ type options struct {
 	// MongoDB configuration
 	mongoDBVersion string // default: "latest"
 	topology       string // server, replica_set, sharded_cluster
+	downloadURL    string // overrides mongoDBVersion when set
+	storageEngine  string // empty = server default (wiredTiger)
 
 	// Docker configuration
 	detPath         string // path to drivers-evergreen-tools repo
 	dockerfile      string // default: .evergreen/docker/ubuntu22.04/Dockerfile
 	mongoClientOpts *mongooptions.ClientOptions
+
+	// Auth configuration
+	authUser     string // empty = noauth
+	authPassword string
+
+	// SSL configuration
+	ssl       bool
+	tlsConfig *tls.Config // populated from the container's certs when ssl is set
+
+	// OCSP configuration
+	ocspMode string // empty = no OCSP
+
+	// Proxy configuration
+	socks5     bool
+	socks5Auth bool
+
+	// Load balancer configuration
+	loadBalancer bool
+
+	// Stable API configuration
+	requireAPIVersion bool
+
+	// Orchestration configuration
+	orchestrationFile string // host path; empty = DET default for topology
+
+	// Network configuration
+	bridgeNetwork bool
+	basePort      int         // 0 = discover free ports
+	portMap       map[int]int // stock config port -> host port, bridge network only
+
+	// Caching configuration
+	sharedContainer bool
+
+	// Atlas Data Lake configuration
+	dataLake bool
 }
 
 // Option is a functional option for configuring the MongoDB container.
@@ -44,6 +88,22 @@ func WithMongoDBVersion(version string) Option {
 	}
 }
 
+// WithDownloadURL installs the server from the given archive URL instead of
+// resolving MongoDBVersion, e.g. to orchestrate a nightly or patch build.
+func WithDownloadURL(url string) Option {
+	return func(o *options) {
+		o.downloadURL = url
+	}
+}
+
+// WithStorageEngine runs the orchestration with an alternate storage engine,
+// e.g. "inmemory". DET selects the matching basic-<engine>.json config.
+func WithStorageEngine(engine string) Option {
+	return func(o *options) {
+		o.storageEngine = engine
+	}
+}
+
 // WithTopology sets the MongoDB topology (server, replica_set, sharded_cluster).
 func WithTopology(topology string) Option {
 	return func(o *options) {
@@ -72,94 +132,271 @@ func WithMongoClientOptions(opts *mongooptions.ClientOptions) Option {
 	}
 }
 
-// New creates a new MongoDB container with the given options.
-func New(t *testing.T, ctx context.Context, opts ...Option) (*mongo.Client, TeardownFunc) {
-	t.Helper()
+// WithAuth runs the orchestration with AUTH=auth and connects as the given
+// user, whose credentials are included in the connection URI. DET's auth
+// configs bootstrap DefaultAuthUser; any other user is created (with the root
+// role) through that account once it is available.
+func WithAuth(user, password string) Option {
+	return func(o *options) {
+		o.authUser = user
+		o.authPassword = password
+	}
+}
+
+// Env provides access to the underlying orchestrated deployment.
+type Env struct {
+	connString string
+	tlsConfig  *tls.Config
+
+	singleLBURI string
+	multiLBURI  string
+
+	orchestration *OrchestrationClient
+	nodes         []Node
+
+	ocspResponderURL string
+	socks5           *Socks5Proxy
+
+	container testcontainers.Container
+}
 
-	settings := &options{
+// ConnectionString returns the MongoDB connection URI.
+func (e *Env) ConnectionString() string {
+	return e.connString
+}
+
+// TLSConfig returns the client TLS config built from the DET x509
+// certificates, or nil if WithSSL was not used.
+func (e *Env) TLSConfig() *tls.Config {
+	return e.tlsConfig
+}
+
+// LoadBalancerURI returns the URI of the load balancer fronting a single
+// mongos, or "" if WithLoadBalancer was not used.
+func (e *Env) LoadBalancerURI() string {
+	return e.singleLBURI
+}
+
+// MultiLoadBalancerURI returns the URI of the load balancer fronting both
+// mongos routers, or "" if WithLoadBalancer was not used.
+func (e *Env) MultiLoadBalancerURI() string {
+	return e.multiLBURI
+}
+
+// Orchestration returns a client for the mongo-orchestration REST API
+// managing the deployment.
+func (e *Env) Orchestration() *OrchestrationClient {
+	return e.orchestration
+}
+
+// WithRequireAPIVersion runs the orchestration with REQUIRE_API_VERSION=1, so
+// the server rejects commands that do not declare an API version. The
+// returned client (and ServerAPIClientOptions) declare API version 1.
+func WithRequireAPIVersion() Option {
+	return func(o *options) {
+		o.requireAPIVersion = true
+	}
+}
+
+// ServerAPIClientOptions returns client options declaring stable API version
+// 1, for connecting additional clients to a deployment started with
+// WithRequireAPIVersion.
+func ServerAPIClientOptions() *mongooptions.ClientOptions {
+	return mongooptions.Client().SetServerAPIOptions(mongooptions.ServerAPI(mongooptions.ServerAPIVersion1))
+}
+
+func requireAPIVersionMode(cfg *options) string {
+	if cfg.requireAPIVersion {
+		return "1"
+	}
+
+	return ""
+}
+
+// defaultOptions returns the settings used when no options are given.
+func defaultOptions() *options {
+	return &options{
 		mongoDBVersion: "latest",
 		topology:       "server",
 		detPath:        os.Getenv("DRIVERS_TOOLS"),
 		dockerfile:     ".evergreen/docker/ubuntu22.04/Dockerfile",
 	}
+}
+
+// skipWithoutDET skips the test unless the DET path and Dockerfile exist.
+func skipWithoutDET(t *testing.T, cfg *options) {
+	t.Helper()
+
+	if _, err := os.Stat(cfg.detPath); os.IsNotExist(err) {
+		t.Skipf("DET path %s does not exist", cfg.detPath)
+	}
+
+	dockerfilePath := filepath.Join(cfg.detPath, cfg.dockerfile)
+	if _, err := os.Stat(dockerfilePath); os.IsNotExist(err) {
+		t.Skipf("Dockerfile %s does not exist in DET path %s", cfg.dockerfile, cfg.detPath)
+	}
+}
+
+// New creates a new MongoDB container with the given options.
+func New(t *testing.T, ctx context.Context, opts ...Option) (*mongo.Client, TeardownFunc) {
+	t.Helper()
+
+	client, teardown, _ := newT(t, ctx, opts...)
+
+	return client, teardown
+}
+
+// NewWithEnv creates a new MongoDB container with the given options and
+// returns a connected mongo.Client, a TeardownFunc, and an Env describing the
+// deployment.
+func NewWithEnv(t *testing.T, ctx context.Context, opts ...Option) (*mongo.Client, TeardownFunc, *Env) {
+	t.Helper()
 
+	return newT(t, ctx, opts...)
+}
+
+func newT(t *testing.T, ctx context.Context, opts ...Option) (*mongo.Client, TeardownFunc, *Env) {
+	t.Helper()
+
+	settings := defaultOptions()
 	for _, apply := range opts {
 		apply(settings)
 	}
 
-	// The detPath and dockerfile have to exist. If not the test must be skipped.
-	if _, err := os.Stat(settings.detPath); os.IsNotExist(err) {
-		t.Skipf("DET path %s does not exist", settings.detPath)
+	// Load balancers front mongos routers.
+	if settings.loadBalancer {
+		settings.topology = "sharded_cluster"
 	}
 
-	dockerfilePath := filepath.Join(settings.detPath, settings.dockerfile)
-	if _, err := os.Stat(dockerfilePath); os.IsNotExist(err) {
-		t.Skipf("Dockerfile %s does not exist in DET path %s", settings.dockerfile, settings.detPath)
+	// DET's OCSP configs are standalone servers.
+	if settings.ocspMode != "" {
+		settings.topology = "server"
 	}
 
-	req := testcontainers.ContainerRequest{
-		FromDockerfile: testcontainers.FromDockerfile{
-			Context:    settings.detPath,
-			Dockerfile: settings.dockerfile,
-			Repo:       "det-mongodb",
-			Tag:        "latest",
-			KeepImage:  true,
-		},
-		Env: map[string]string{
-			"MONGODB_VERSION":       settings.mongoDBVersion,
-			"TOPOLOGY":              settings.topology,
-			"AUTH":                  "noauth",
-			"SSL":                   "nossl",
-			"ORCHESTRATION_FILE":    "",
-			"LOAD_BALANCER":         "",
-			"STORAGE_ENGINE":        "",
-			"REQUIRE_API_VERSION":   "",
-			"DISABLE_TEST_COMMANDS": "",
-			"MONGODB_DOWNLOAD_URL":  "",
-		},
-		Entrypoint: []string{"/root/local-entrypoint.sh"},
-		// Use host network mode so replica set members on 127.0.0.1 are accessible
-		NetworkMode: "host",
-		WaitingFor:  wait.ForLog("send_result(200)"),
+	skipWithoutDET(t, settings)
+
+	if settings.orchestrationFile != "" {
+		_, err := os.Stat(settings.orchestrationFile)
+		require.NoError(t, err, "orchestration file must exist")
 	}
 
-	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          true,
-	})
-	require.NoError(t, err, "failed to start atlaslocal container")
+	if settings.bridgeNetwork {
+		require.False(t, settings.loadBalancer, "WithLoadBalancer is not supported with WithBridgeNetwork")
+		require.NoError(t, configureBridgeNetwork(settings, t.TempDir()), "failed to configure bridge network")
+		t.Cleanup(func() { releasePorts(settings.portMap) })
+	}
+
+	if settings.sharedContainer {
+		require.False(t, settings.bridgeNetwork, "WithSharedContainer is not supported with WithBridgeNetwork")
+	}
+
+	var (
+		dep *deployment
+		err error
+	)
+
+	switch {
+	case settings.dataLake:
+		pullDataLakeImage(t, ctx, settings)
+		dep, err = startDataLake(ctx, settings)
+	case settings.sharedContainer:
+		dep, err = sharedDeployment(t, ctx, settings)
+	default:
+		dep, err = provision(t, ctx, settings)
+	}
+
+	// Shared deployments outlive the test; they are reaped when the test
+	// binary exits.
+	keepContainer := settings.sharedContainer && err == nil
 
 	tdFunc := func(t *testing.T) {
 		t.Helper()
 
-		require.NoError(t, testcontainers.TerminateContainer(container),
+		if t.Failed() && dep.container != nil {
+			collectOrchestrationLogs(t, ctx, dep.container)
+		}
+
+		if keepContainer {
+			return
+		}
+
+		if dep.stop != nil {
+			require.NoError(t, dep.stop(ctx), "failed to stop deployment")
+		}
+
+		require.NoError(t, testcontainers.TerminateContainer(dep.container),
 			"failed to terminate atlaslocal container")
 	}
 
-	// Get connection URI based on topology
-	connString, err := buildConnectionURI(ctx, container, settings)
-	require.NoError(t, err, "failed to build connection URI")
+	// fatalf fails the test before tearing down, so the orchestration logs
+	// are collected.
+	fatalf := func(format string, args ...any) {
+		t.Helper()
+
+		t.Errorf(format, args...)
+		tdFunc(t)
+		t.FailNow()
+	}
+
+	if err != nil {
+		fatalf("failed to provision DET deployment: %s", err)
+	}
+
+	settings.tlsConfig = dep.tlsConfig
+	connString := dep.connString
 
 	mopts := settings.mongoClientOpts
 	if mopts == nil {
 		mopts = mongooptions.Client()
 	}
 
+	// ApplyURI populates a default TLSConfig for tls=true, so check for a
+	// user-provided one first.
+	userTLSConfig := mopts.TLSConfig != nil
+
 	// Users can't override the connection string.
 	mopts = mopts.ApplyURI(connString)
 
+	if settings.tlsConfig != nil && !userTLSConfig {
+		mopts = mopts.SetTLSConfig(settings.tlsConfig)
+	}
+
+	if settings.requireAPIVersion && mopts.ServerAPIOptions == nil {
+		mopts = mopts.SetServerAPIOptions(mongooptions.ServerAPI(mongooptions.ServerAPIVersion1))
+	}
+
 	mongoClient, err := mongo.Connect(mopts)
 	if err != nil {
-		tdFunc(t)
-		t.Fatalf("failed to connect to mongo: %s", err)
+		fatalf("failed to connect to mongo: %s", err)
 	}
 
 	pingCtx, pingCancel := context.WithTimeout(ctx, 1*time.Second)
 	defer pingCancel()
 
 	if err := mongoClient.Ping(pingCtx, nil); err != nil {
-		tdFunc(t)
-		t.Fatalf("failed to ping mongo: %s", err)
+		fatalf("failed to ping mongo: %s", err)
+	}
+
+	env := &Env{
+		connString:    connString,
+		tlsConfig:     settings.tlsConfig,
+		orchestration: NewOrchestrationClient(dep.orchestrationURL),
+		socks5:        dep.socks5,
+		container:     dep.container,
+	}
+
+	env.nodes, err = discoverNodes(ctx, settings, env.orchestration)
+	if err != nil {
+		fatalf("failed to discover nodes: %s", err)
+	}
+
+	if settings.ocspMode != "" && settings.ocspMode != OCSPNoResponder {
+		env.ocspResponderURL = ocspResponderURL
+	}
+
+	if settings.loadBalancer {
+		env.singleLBURI = connString
+		env.multiLBURI = loadBalancerURI(settings, multiLoadBalancerPort)
 	}
 
 	return mongoClient, func(t *testing.T) {
@@ -167,28 +404,356 @@ func New(t *testing.T, ctx context.Context, opts ...Option) (*mongo.Client, Tear
 
 		require.NoError(t, mongoClient.Disconnect(ctx), "failed to disconnect mongo client")
 		tdFunc(t)
+	}, env
+}
+
+// deployment is a provisioned DET container.
+type deployment struct {
+	container        testcontainers.Container
+	connString       string
+	tlsConfig        *tls.Config
+	orchestrationURL string
+	socks5           *Socks5Proxy
+
+	// stop tears down anything started outside the container.
+	stop func(context.Context) error
+}
+
+// provision starts the DET container and waits until the deployment is
+// usable. On error the returned deployment still holds the container, if one
+// was started, so the caller can collect its logs and terminate it.
+func provision(t *testing.T, ctx context.Context, settings *options) (*deployment, error) {
+	t.Helper()
+
+	req := testcontainers.ContainerRequest{
+		Env: map[string]string{
+			"MONGODB_VERSION":       settings.mongoDBVersion,
+			"TOPOLOGY":              settings.topology,
+			"AUTH":                  authMode(settings),
+			"SSL":                   sslMode(settings),
+			"ORCHESTRATION_FILE":    orchestrationFileName(settings),
+			"LOAD_BALANCER":         loadBalancerMode(settings),
+			"STORAGE_ENGINE":        settings.storageEngine,
+			"REQUIRE_API_VERSION":   requireAPIVersionMode(settings),
+			"DISABLE_TEST_COMMANDS": "",
+			"MONGODB_DOWNLOAD_URL":  settings.downloadURL,
+		},
+		Files:      orchestrationFiles(settings),
+		Entrypoint: []string{"/root/local-entrypoint.sh"},
+		WaitingFor: wait.ForLog("send_result(200)"),
+	}
+
+	if settings.ocspMode != "" {
+		req.Entrypoint = ocspEntrypoint(settings)
+	}
+
+	if err := setImage(ctx, &req, settings); err != nil {
+		return &deployment{}, err
+	}
+
+	configureNetwork(&req, settings)
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+
+	// A container whose orchestration failed is still returned with the
+	// error, but one that was never created comes back as a typed nil.
+	if dc, ok := container.(*testcontainers.DockerContainer); ok && dc == nil {
+		container = nil
+	}
+
+	dep := &deployment{container: container}
+	if err != nil {
+		return dep, fmt.Errorf("start container: %w", err)
+	}
+
+	if settings.ssl {
+		caFile, clientFile, err := copyX509Certs(ctx, container, t.TempDir())
+		if err != nil {
+			return dep, fmt.Errorf("copy x509 certificates: %w", err)
+		}
+
+		settings.tlsConfig, err = newTLSConfig(caFile, clientFile)
+		if err != nil {
+			return dep, fmt.Errorf("build TLS config: %w", err)
+		}
+
+		dep.tlsConfig = settings.tlsConfig
+	}
+
+	if settings.ocspMode != "" {
+		settings.tlsConfig, err = newOCSPTLSConfig(ctx, container)
+		if err != nil {
+			return dep, fmt.Errorf("build OCSP TLS config: %w", err)
+		}
+
+		dep.tlsConfig = settings.tlsConfig
+	}
+
+	dep.orchestrationURL, err = orchestrationBaseURL(ctx, container, settings)
+	if err != nil {
+		return dep, fmt.Errorf("resolve orchestration URL: %w", err)
+	}
+
+	// Get connection URI based on topology
+	dep.connString, err = buildConnectionURI(ctx, container, settings)
+	if err != nil {
+		return dep, fmt.Errorf("build connection URI: %w", err)
+	}
+
+	// The orchestration reports success once the cluster is provisioned, but
+	// each mongos may still be starting up; wait so tests actually hit both
+	// routers.
+	if settings.topology == "sharded_cluster" {
+		if err := waitForMongos(ctx, settings, mongosHosts(settings), mongosReadyTimeout); err != nil {
+			return dep, fmt.Errorf("wait for mongos: %w", err)
+		}
 	}
+
+	if settings.loadBalancer {
+		if err := startLoadBalancer(ctx, container, settings); err != nil {
+			return dep, fmt.Errorf("start load balancer: %w", err)
+		}
+	}
+
+	if settings.authUser != "" {
+		if err := bootstrapUser(ctx, settings, authBootstrapTimeout); err != nil {
+			return dep, fmt.Errorf("bootstrap user %q: %w", settings.authUser, err)
+		}
+	}
+
+	if settings.socks5 {
+		if dep.socks5, err = startSocks5Proxy(ctx, container, settings); err != nil {
+			return dep, fmt.Errorf("start SOCKS5 proxy: %w", err)
+		}
+	}
+
+	return dep, nil
+}
+
+// mongosPorts are the ports of the mongos routers started by the DET
+// sharded_cluster orchestration config.
+var mongosPorts = []int{27017, 27018}
+
+// mongosHosts returns the addresses of the mongos routers.
+func mongosHosts(cfg *options) []string {
+	hosts := make([]string, 0, len(mongosPorts))
+	for _, port := range mongosPorts {
+		hosts = append(hosts, hostAddr(cfg, port))
+	}
+
+	return hosts
+}
+
+// hostAddr returns the address of the member the stock configs place on
+// port, accounting for ports remapped by WithBridgeNetwork.
+func hostAddr(cfg *options, port int) string {
+	if mapped, ok := cfg.portMap[port]; ok {
+		port = mapped
+	}
+
+	return "localhost:" + strconv.Itoa(port)
+}
+
+const mongosReadyTimeout = 60 * time.Second
+
+// DefaultAuthUser and DefaultAuthPassword are the credentials created by the
+// DET auth orchestration configs.
+const (
+	DefaultAuthUser     = "bob"
+	DefaultAuthPassword = "pwd123"
+)
+
+const authBootstrapTimeout = 60 * time.Second
+
+// errCodeUserExists is returned by createUser when the user already exists.
+const errCodeUserExists = 51003
+
+func authMode(cfg *options) string {
+	if cfg.authUser != "" {
+		return "auth"
+	}
+
+	return "noauth"
 }
 
 func buildConnectionURI(ctx context.Context, container testcontainers.Container, cfg *options) (string, error) {
-	// With host networking, MongoDB is accessible on localhost with standard ports
-	if cfg.topology == "replica_set" {
+	if cfg.loadBalancer {
+		return loadBalancerURI(cfg, singleLoadBalancerPort), nil
+	}
+
+	query := url.Values{}
+
+	var hosts string
+
+	// MongoDB is accessible on localhost with the standard ports, or the
+	// ports they were remapped to on the bridge network.
+	switch cfg.topology {
+	case "replica_set":
 		// Connect to all three replica set members on localhost
 		// The replica set name is "repl0" based on the orchestration config
-		return "mongodb://localhost:27017,localhost:27018,localhost:27019/?replicaSet=repl0", nil
+		hosts = strings.Join([]string{hostAddr(cfg, 27017), hostAddr(cfg, 27018), hostAddr(cfg, 27019)}, ",")
+		query.Set("replicaSet", ReplicaSetID)
+	case "sharded_cluster":
+		// Connect to both mongos routers.
+		hosts = strings.Join(mongosHosts(cfg), ",")
+	default:
+		// Standalone server
+		hosts = hostAddr(cfg, 27017)
+	}
+
+	return formatURI(cfg, hosts, query), nil
+}
+
+// formatURI builds a connection URI for hosts, adding the credentials and
+// TLS settings from cfg to query.
+func formatURI(cfg *options, hosts string, query url.Values) string {
+	uri := &url.URL{Scheme: "mongodb", Host: hosts, Path: "/"}
+
+	if cfg.authUser != "" {
+		uri.User = url.UserPassword(cfg.authUser, cfg.authPassword)
+		query.Set("authSource", "admin")
+	}
+
+	if usesTLS(cfg) {
+		query.Set("tls", "true")
+	}
+
+	uri.RawQuery = query.Encode()
+
+	return uri.String()
+}
+
+// internalClientOptions returns the options for clients det uses internally
+// to probe the deployment at uri. The TLS config is applied after the URI
+// since ApplyURI replaces it for tls=true.
+func internalClientOptions(cfg *options, uri string) *mongooptions.ClientOptions {
+	opts := mongooptions.Client().ApplyURI(uri)
+	if cfg.tlsConfig != nil {
+		opts = opts.SetTLSConfig(cfg.tlsConfig)
+	}
+
+	if cfg.requireAPIVersion {
+		opts = opts.SetServerAPIOptions(mongooptions.ServerAPI(mongooptions.ServerAPIVersion1))
 	}
 
-	// Standalone server
-	return "mongodb://localhost:27017", nil
+	return opts
+}
+
+// waitForMongos pings each mongos over a direct connection until all of them
+// respond as routers or the timeout expires.
+func waitForMongos(ctx context.Context, cfg *options, hosts []string, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for _, host := range hosts {
+		client, err := mongo.Connect(internalClientOptions(cfg, "mongodb://"+host).SetDirect(true))
+		if err != nil {
+			return fmt.Errorf("connect to mongos %s: %w", host, err)
+		}
+
+		err = awaitRouter(waitCtx, client)
+		_ = client.Disconnect(ctx)
+
+		if err != nil {
+			return fmt.Errorf("mongos %s not ready: %w", host, err)
+		}
+	}
+
+	return nil
+}
+
+// bootstrapUser waits until the orchestration's default user can
+// authenticate and, if cfg requests a different user, creates it.
+func bootstrapUser(ctx context.Context, cfg *options, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	defaultCfg := *cfg
+	defaultCfg.authUser = DefaultAuthUser
+	defaultCfg.authPassword = DefaultAuthPassword
+
+	uri, err := buildConnectionURI(ctx, nil, &defaultCfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := mongo.Connect(internalClientOptions(cfg, uri))
+	if err != nil {
+		return fmt.Errorf("connect as %s: %w", DefaultAuthUser, err)
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	admin := client.Database("admin")
+
+	for {
+		err := admin.RunCommand(waitCtx, bson.D{{Key: "ping", Value: 1}}).Err()
+		if err == nil {
+			break
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return fmt.Errorf("authenticate as %s: %w (last error: %v)", DefaultAuthUser, waitCtx.Err(), err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+
+	if cfg.authUser == DefaultAuthUser {
+		return nil
+	}
+
+	err = admin.RunCommand(waitCtx, bson.D{
+		{Key: "createUser", Value: cfg.authUser},
+		{Key: "pwd", Value: cfg.authPassword},
+		{Key: "roles", Value: bson.A{bson.D{{Key: "role", Value: "root"}, {Key: "db", Value: "admin"}}}},
+	}).Err()
+
+	var se mongo.ServerError
+	if err != nil && (!errors.As(err, &se) || !se.HasErrorCode(errCodeUserExists)) {
+		return fmt.Errorf("create user: %w", err)
+	}
+
+	return nil
+}
+
+// awaitRouter polls hello until the server identifies itself as a mongos.
+func awaitRouter(ctx context.Context, client *mongo.Client) error {
+	var lastErr error
+	for {
+		var hello struct {
+			Msg string `bson:"msg"`
+		}
+
+		err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+		if err == nil && hello.Msg == "isdbgrid" {
+			return nil
+		}
+
+		lastErr = err
+		if lastErr == nil {
+			lastErr = fmt.Errorf("hello msg is %q, want \"isdbgrid\"", hello.Msg)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
 }
 
-func getExposedPorts(topology string) []string {
+// topologyPorts returns the ports clients connect to for the topology in
+// the stock orchestration configs.
+func topologyPorts(topology string) []int {
 	switch topology {
 	case "replica_set":
-		return []string{"27017/tcp", "27018/tcp", "27019/tcp"}
+		return []int{27017, 27018, 27019}
 	case "sharded_cluster":
-		return []string{"27017/tcp", "27018/tcp"} // mongos ports
+		return mongosPorts
 	default: // server (standalone)
-		return []string{"27017/tcp"}
+		return []int{27017}
 	}
 }
@@ -0,0 +1,83 @@
+package det
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// electionTimeout bounds how long KillPrimary waits for a new primary.
+const electionTimeout = 60 * time.Second
+
+// RestartMember restarts the replica set member listening on host (as it
+// appears in the connection string, e.g. "localhost:27018") through
+// mongo-orchestration. A stopped member is started again.
+func (e *Env) RestartMember(t *testing.T, host string) {
+	t.Helper()
+
+	ctx := t.Context()
+
+	member, err := e.member(ctx, host)
+	require.NoError(t, err, "failed to find member %s", host)
+
+	err = e.orchestration.ServerAction(ctx, member.ServerID, ServerRestart, 0)
+	require.NoError(t, err, "failed to restart member %s", host)
+}
+
+// KillPrimary stops the current primary and waits until the remaining
+// members elect a new one, returning the stopped member. Bring it back with
+// RestartMember.
+func (e *Env) KillPrimary(t *testing.T) *Member {
+	t.Helper()
+
+	ctx := t.Context()
+
+	primary, err := e.orchestration.Primary(ctx, ReplicaSetID)
+	require.NoError(t, err, "failed to find primary")
+
+	err = e.orchestration.ServerAction(ctx, primary.ServerID, ServerStop, 0)
+	require.NoError(t, err, "failed to stop primary %s", primary.Host)
+
+	electCtx, cancel := context.WithTimeout(ctx, electionTimeout)
+	defer cancel()
+
+	require.NoError(t, e.awaitNewPrimary(electCtx, primary.Host), "no new primary elected")
+
+	return primary
+}
+
+// member returns the replica set member listening on host.
+func (e *Env) member(ctx context.Context, host string) (*Member, error) {
+	members, err := e.orchestration.Members(ctx, ReplicaSetID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range members {
+		if m.Host == host {
+			return &m, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no member of %s listens on %s", ReplicaSetID, host)
+}
+
+// awaitNewPrimary polls until a member other than oldHost is primary.
+func (e *Env) awaitNewPrimary(ctx context.Context, oldHost string) error {
+	for {
+		// The primary endpoint errors while there is no primary.
+		primary, err := e.orchestration.Primary(ctx, ReplicaSetID)
+		if err == nil && primary.Host != oldHost {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
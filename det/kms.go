@@ -0,0 +1,222 @@
+package det
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Mock KMS providers shipped in DET's .evergreen/csfle directory.
+const (
+	// KMSKMIP is the KMIP server, using the DET server certificate.
+	KMSKMIP = "kmip"
+
+	// KMSAzureIMDS is the Azure Instance Metadata Service mock that hands out
+	// access tokens for automatic Azure credentials.
+	KMSAzureIMDS = "azure"
+
+	// KMSHTTP is the set of mock KMS HTTP endpoints used by the CSFLE spec
+	// tests: one with an expired certificate, one with a certificate for the
+	// wrong host, and one with a valid certificate.
+	KMSHTTP = "http"
+
+	// KMSFailpoint is the KMS mock whose responses can be failed on demand.
+	KMSFailpoint = "failpoint"
+)
+
+// kmsServer is a mock KMS server started from DET's csfle directory.
+type kmsServer struct {
+	name string
+	port int
+	cmd  string // run from the csfle directory with the kms venv active
+}
+
+// kmsServers maps each provider to the servers it starts, mirroring DET's
+// csfle/start-servers.sh.
+var kmsServers = map[string][]kmsServer{
+	KMSKMIP: {
+		{name: "kmip", port: 5698, cmd: "python -u kms_kmip_server.py --ca_file ../x509gen/ca.pem --cert_file ../x509gen/server.pem --port 5698"},
+	},
+	KMSAzureIMDS: {
+		{name: "azure", port: 8080, cmd: "python -u bottle.py fake_azure:imds"},
+	},
+	KMSHTTP: {
+		{name: "expired", port: 8000, cmd: "python -u kms_http_server.py --ca_file ../x509gen/ca.pem --cert_file ../x509gen/expired.pem --port 8000"},
+		{name: "wrong-host", port: 8001, cmd: "python -u kms_http_server.py --ca_file ../x509gen/ca.pem --cert_file ../x509gen/wrong-host.pem --port 8001"},
+		{name: "valid", port: 8002, cmd: "python -u kms_http_server.py --ca_file ../x509gen/ca.pem --cert_file ../x509gen/server.pem --port 8002 --require_client_cert"},
+	},
+	KMSFailpoint: {
+		{name: "failpoint", port: 9003, cmd: "python -u kms_failpoint_server.py --port 9003"},
+	},
+}
+
+// kmsReadyTimeout bounds how long NewKMS waits for each server to listen.
+// Creating the venv on first use dominates.
+const kmsReadyTimeout = 5 * time.Minute
+
+// KMSEnv describes the mock KMS servers started by NewKMS.
+type KMSEnv struct {
+	endpoints map[string]string
+	tlsConfig *tls.Config
+}
+
+// Endpoint returns the host:port of the named server, e.g. "kmip", "azure",
+// "failpoint", or one of the KMSHTTP servers "expired", "wrong-host", and
+// "valid". It returns "" if the server was not started.
+func (k *KMSEnv) Endpoint(name string) string {
+	return k.endpoints[name]
+}
+
+// TLSConfig returns a TLS config trusting the DET CA and presenting the DET
+// client certificate, as the KMIP and HTTP mocks expect. Use it for the
+// providers' entries in the ClientEncryption TLS options.
+func (k *KMSEnv) TLSConfig() *tls.Config {
+	return k.tlsConfig
+}
+
+// KMIPProvider returns the kmsProviders entry for the KMIP mock.
+func (k *KMSEnv) KMIPProvider() map[string]any {
+	return map[string]any{"endpoint": k.endpoints["kmip"]}
+}
+
+// NewKMS starts DET's mock KMS servers for the given providers (KMSKMIP,
+// KMSAzureIMDS, KMSHTTP, KMSFailpoint; all of them if none are given) in a
+// DET container on the host network, so CSFLE flows can run locally. The
+// servers listen on DET's fixed ports, so NewKMS can't run in parallel with
+// itself or with WithLoadBalancer.
+func NewKMS(t *testing.T, ctx context.Context, providers ...string) (*KMSEnv, TeardownFunc) {
+	t.Helper()
+
+	settings := defaultOptions()
+	skipWithoutDET(t, settings)
+
+	if len(providers) == 0 {
+		for provider := range kmsServers {
+			providers = append(providers, provider)
+		}
+
+		slices.Sort(providers)
+	}
+
+	for _, provider := range providers {
+		require.Contains(t, kmsServers, provider, "unsupported KMS provider")
+	}
+
+	// Keep the container alive without starting an orchestration.
+	req := testcontainers.ContainerRequest{
+		Entrypoint:  []string{"sleep", "infinity"},
+		NetworkMode: "host",
+		WaitingFor:  wait.ForExec([]string{"true"}),
+	}
+
+	require.NoError(t, setImage(ctx, &req, settings), "failed to resolve DET image")
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+
+	tdFunc := func(t *testing.T) {
+		t.Helper()
+
+		require.NoError(t, testcontainers.TerminateContainer(container),
+			"failed to terminate KMS container")
+	}
+
+	if err != nil {
+		tdFunc(t)
+		t.Fatalf("failed to start KMS container: %s", err)
+	}
+
+	kms := &KMSEnv{endpoints: map[string]string{}}
+
+	for _, provider := range providers {
+		for _, srv := range kmsServers[provider] {
+			if err := startKMSServer(ctx, container, srv); err != nil {
+				tdFunc(t)
+				t.Fatalf("failed to start %s KMS server: %s", srv.name, err)
+			}
+
+			kms.endpoints[srv.name] = fmt.Sprintf("localhost:%d", srv.port)
+		}
+	}
+
+	caFile, clientFile, err := copyX509Certs(ctx, container, t.TempDir())
+	if err != nil {
+		tdFunc(t)
+		t.Fatalf("failed to copy x509 certificates: %s", err)
+	}
+
+	kms.tlsConfig, err = newTLSConfig(caFile, clientFile)
+	if err != nil {
+		tdFunc(t)
+		t.Fatalf("failed to build TLS config: %s", err)
+	}
+
+	return kms, tdFunc
+}
+
+// startKMSServer starts srv in the background and waits until it accepts
+// connections.
+func startKMSServer(ctx context.Context, container testcontainers.Container, srv kmsServer) error {
+	csfleDir := path.Join(containerDETPath, ".evergreen", "csfle")
+	script := strings.Join([]string{
+		"cd " + csfleDir,
+		". ./activate-kmstlsvenv.sh",
+		fmt.Sprintf("nohup %s > %s.log 2>&1 &", srv.cmd, srv.name),
+	}, " && ")
+
+	if err := execScript(ctx, container, script); err != nil {
+		return err
+	}
+
+	return awaitListening(ctx, fmt.Sprintf("localhost:%d", srv.port), kmsReadyTimeout)
+}
+
+// execScript runs script with bash in the container and fails if it exits
+// non-zero.
+func execScript(ctx context.Context, container testcontainers.Container, script string) error {
+	rc, out, err := container.Exec(ctx, []string{"bash", "-c", script}, exec.Multiplexed())
+	if err != nil {
+		return fmt.Errorf("run %q: %w", script, err)
+	}
+
+	if rc != 0 {
+		output, _ := io.ReadAll(out)
+		return fmt.Errorf("%q exited with %d: %s", script, rc, output)
+	}
+
+	return nil
+}
+
+// awaitListening polls until addr accepts TCP connections or the timeout
+// expires.
+func awaitListening(ctx context.Context, addr string, timeout time.Duration) error {
+	readyCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		conn, err := (&net.Dialer{}).DialContext(readyCtx, "tcp", addr)
+		if err == nil {
+			return conn.Close()
+		}
+
+		select {
+		case <-readyCtx.Done():
+			return fmt.Errorf("nothing listening on %s: %w", addr, readyCtx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
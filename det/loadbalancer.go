@@ -0,0 +1,67 @@
+package det
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/exec"
+)
+
+// Ports haproxy listens on when started by DET's run-load-balancer.sh. The
+// single-mongos port fronts only the first router.
+const (
+	singleLoadBalancerPort = 8000
+	multiLoadBalancerPort  = 8001
+)
+
+// WithLoadBalancer runs a sharded cluster with LOAD_BALANCER=1 and starts
+// DET's haproxy load balancer inside the container. The returned client
+// connects through the single-mongos load balancer with loadBalanced=true;
+// both load balancer URIs are available from Env.
+//
+// The topology is forced to sharded_cluster.
+func WithLoadBalancer() Option {
+	return func(o *options) {
+		o.loadBalancer = true
+	}
+}
+
+func loadBalancerMode(cfg *options) string {
+	if cfg.loadBalancer {
+		return "1"
+	}
+
+	return ""
+}
+
+// startLoadBalancer runs DET's run-load-balancer.sh in the container,
+// pointing haproxy at both mongos routers.
+func startLoadBalancer(ctx context.Context, container testcontainers.Container, cfg *options) error {
+	script := path.Join(containerDETPath, ".evergreen", "run-load-balancer.sh")
+	mongosURI := "mongodb://" + strings.Join(mongosHosts(cfg), ",")
+
+	cmd := []string{"bash", "-c", fmt.Sprintf("MONGODB_URI=%q %s start", mongosURI, script)}
+
+	rc, out, err := container.Exec(ctx, cmd, exec.Multiplexed())
+	if err != nil {
+		return fmt.Errorf("run load balancer script: %w", err)
+	}
+
+	if rc != 0 {
+		output, _ := io.ReadAll(out)
+		return fmt.Errorf("load balancer script exited with %d: %s", rc, output)
+	}
+
+	return nil
+}
+
+// loadBalancerURI returns the URI for the load balancer listening on port.
+func loadBalancerURI(cfg *options, port int) string {
+	return formatURI(cfg, "127.0.0.1:"+strconv.Itoa(port), url.Values{"loadBalanced": {"true"}})
+}
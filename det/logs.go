@@ -0,0 +1,97 @@
+package det
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/exec"
+)
+
+// logTailLines is how many trailing lines of each log are attached to the
+// test output.
+const logTailLines = 50
+
+// orchestrationDir is where mongo-orchestration and the servers it manages
+// write their logs inside the container.
+var orchestrationDir = path.Join(containerDETPath, ".evergreen", "orchestration")
+
+// collectOrchestrationLogs copies every log under the container's
+// orchestration directory to a directory on the host that outlives the test,
+// and attaches the tail of each one to the test output. Failures are logged
+// rather than reported, since the test has already failed.
+func collectOrchestrationLogs(t *testing.T, ctx context.Context, container testcontainers.Container) {
+	t.Helper()
+
+	rc, out, err := container.Exec(ctx, []string{"find", orchestrationDir, "-type", "f", "-name", "*.log"},
+		exec.Multiplexed())
+	if err != nil || rc != 0 {
+		t.Logf("det: failed to list orchestration logs (exit %d): %v", rc, err)
+		return
+	}
+
+	listing, _ := io.ReadAll(out)
+
+	dir, err := os.MkdirTemp("", "det-logs-*")
+	if err != nil {
+		t.Logf("det: failed to create log directory: %v", err)
+		return
+	}
+
+	t.Logf("det: orchestration logs saved to %s", dir)
+
+	for _, logPath := range strings.Fields(string(listing)) {
+		rel := strings.TrimPrefix(logPath, orchestrationDir+"/")
+		if err := copyLog(t, ctx, container, logPath, filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+			t.Logf("det: failed to copy %s: %v", logPath, err)
+		}
+	}
+}
+
+// copyLog copies the container log at src to dst and logs its tail.
+func copyLog(t *testing.T, ctx context.Context, container testcontainers.Container, src, dst string) error {
+	t.Helper()
+
+	rc, err := container.CopyFileFromContainer(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tail := make([]string, 0, logTailLines)
+
+	scanner := bufio.NewScanner(io.TeeReader(rc, f))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		if len(tail) == logTailLines {
+			tail = tail[1:]
+		}
+
+		tail = append(tail, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	t.Logf("det: last %d lines of %s:\n%s", len(tail), src, strings.Join(tail, "\n"))
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package det
+
+import (
+	"context"
+)
+
+// NodeRole is the role a process plays in the deployment.
+type NodeRole string
+
+// Node roles.
+const (
+	RolePrimary    NodeRole = "primary"
+	RoleSecondary  NodeRole = "secondary"
+	RoleArbiter    NodeRole = "arbiter"
+	RoleMongos     NodeRole = "mongos"
+	RoleStandalone NodeRole = "standalone"
+	RoleOther      NodeRole = "other" // e.g. a replica set member still recovering
+)
+
+// replica set member states, as reported in replSetGetStatus.
+const (
+	statePrimary   = 1
+	stateSecondary = 2
+	stateArbiter   = 7
+)
+
+// Node describes a process clients can connect to.
+type Node struct {
+	// Host is the host:port clients connect to.
+	Host string
+
+	// Role is the node's role when the Env was created.
+	Role NodeRole
+
+	// ServerID is the mongo-orchestration server ID, usable with
+	// OrchestrationClient.ServerAction. Only set for replica set members.
+	ServerID string
+}
+
+// Nodes returns the client-facing processes of the deployment: the replica
+// set members, the mongos routers, or the standalone server.
+func (e *Env) Nodes() []Node {
+	return append([]Node(nil), e.nodes...)
+}
+
+// NodesWithRole returns the nodes that had role when the Env was created.
+func (e *Env) NodesWithRole(role NodeRole) []Node {
+	var nodes []Node
+	for _, n := range e.nodes {
+		if n.Role == role {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return nodes
+}
+
+// discoverNodes describes the deployment's client-facing processes. Replica
+// set roles come from mongo-orchestration; the other topologies have fixed
+// roles.
+func discoverNodes(ctx context.Context, cfg *options, orch *OrchestrationClient) ([]Node, error) {
+	switch cfg.topology {
+	case "replica_set":
+		members, err := orch.Members(ctx, ReplicaSetID)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes := make([]Node, 0, len(members))
+		for _, m := range members {
+			nodes = append(nodes, Node{Host: m.Host, Role: memberRole(m.State), ServerID: m.ServerID})
+		}
+
+		return nodes, nil
+	case "sharded_cluster":
+		var nodes []Node
+		for _, host := range mongosHosts(cfg) {
+			nodes = append(nodes, Node{Host: host, Role: RoleMongos})
+		}
+
+		return nodes, nil
+	default:
+		return []Node{{Host: hostAddr(cfg, 27017), Role: RoleStandalone}}, nil
+	}
+}
+
+func memberRole(state int) NodeRole {
+	switch state {
+	case statePrimary:
+		return RolePrimary
+	case stateSecondary:
+		return RoleSecondary
+	case stateArbiter:
+		return RoleArbiter
+	default:
+		return RoleOther
+	}
+}
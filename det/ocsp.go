@@ -0,0 +1,100 @@
+package det
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// OCSP responder modes, matching the SERVER_TYPE values understood by DET's
+// ocsp/setup.sh.
+const (
+	OCSPValid           = "valid"
+	OCSPRevoked         = "revoked"
+	OCSPValidDelegate   = "valid-delegate"
+	OCSPRevokedDelegate = "revoked-delegate"
+
+	// OCSPNoResponder starts no responder and disables stapling, exercising
+	// the driver's soft-fail path.
+	OCSPNoResponder = "no-responder"
+)
+
+// ocspResponderURL is where DET's mock OCSP responder listens; the DET OCSP
+// certificates name it in their AIA extension.
+const ocspResponderURL = "http://localhost:8100"
+
+// ocspDir holds DET's OCSP certificate sets and responder scripts.
+const ocspDir = ".evergreen/ocsp"
+
+// WithOCSP runs a standalone TLS server using DET's RSA OCSP certificate set,
+// with DET's mock OCSP responder answering in the given mode (OCSPValid,
+// OCSPRevoked, ...). The server staples OCSP responses, except with
+// OCSPNoResponder. The client trusts the OCSP CA; Env.TLSConfig and
+// Env.OCSPResponderURL expose the setup.
+//
+// The topology is forced to server.
+func WithOCSP(mode string) Option {
+	return func(o *options) {
+		o.ocspMode = mode
+	}
+}
+
+// ocspOrchestrationFile returns the DET server config for the OCSP mode.
+func ocspOrchestrationFile(cfg *options) string {
+	if cfg.ocspMode == OCSPNoResponder {
+		return "rsa-basic-tls-ocsp-disableStapling.json"
+	}
+
+	return "rsa-basic-tls-ocsp-mustStaple.json"
+}
+
+// ocspEntrypoint starts the responder before the orchestration, so the
+// server can staple a response from startup.
+func ocspEntrypoint(cfg *options) []string {
+	if cfg.ocspMode == OCSPNoResponder {
+		return []string{"/root/local-entrypoint.sh"}
+	}
+
+	script := strings.Join([]string{
+		fmt.Sprintf("OCSP_ALGORITHM=rsa SERVER_TYPE=%s bash %s", cfg.ocspMode,
+			path.Join(containerDETPath, ocspDir, "setup.sh")),
+		"exec /root/local-entrypoint.sh",
+	}, " && ")
+
+	return []string{"bash", "-c", script}
+}
+
+// newOCSPTLSConfig returns a client TLS config trusting the OCSP CA in the
+// container. The OCSP servers don't require client certificates.
+func newOCSPTLSConfig(ctx context.Context, container testcontainers.Container) (*tls.Config, error) {
+	r, err := container.CopyFileFromContainer(ctx, path.Join(containerDETPath, ocspDir, "rsa", "ca.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("copy OCSP CA from container: %w", err)
+	}
+	defer r.Close()
+
+	caPEM, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read OCSP CA: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("no certificates found in OCSP CA file")
+	}
+
+	return &tls.Config{RootCAs: roots}, nil
+}
+
+// OCSPResponderURL returns the URL of the mock OCSP responder, or "" if
+// WithOCSP was not used or no responder was started.
+func (e *Env) OCSPResponderURL() string {
+	return e.ocspResponderURL
+}
@@ -0,0 +1,58 @@
+package det
+
+import (
+	"path"
+	"path/filepath"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// orchestrationConfigDirs maps a TOPOLOGY value to the directory under
+// .evergreen/orchestration/configs where drivers_orchestration.py resolves
+// ORCHESTRATION_FILE.
+var orchestrationConfigDirs = map[string]string{
+	"server":          "servers",
+	"replica_set":     "replica_sets",
+	"sharded_cluster": "sharded_clusters",
+}
+
+// WithOrchestrationFile mounts a custom mongo-orchestration JSON config into
+// the container and selects it via ORCHESTRATION_FILE, enabling topologies
+// the stock configs don't cover (arbiters, hidden members, delayed
+// secondaries, ...). The config must match the chosen topology, and the
+// connection URI still assumes the standard ports for that topology.
+func WithOrchestrationFile(hostPath string) Option {
+	return func(o *options) {
+		o.orchestrationFile = hostPath
+	}
+}
+
+// orchestrationFileName returns the ORCHESTRATION_FILE value, or "" to use
+// the DET default.
+func orchestrationFileName(cfg *options) string {
+	switch {
+	case cfg.orchestrationFile != "":
+		return filepath.Base(cfg.orchestrationFile)
+	case cfg.ocspMode != "":
+		return ocspOrchestrationFile(cfg)
+	default:
+		return ""
+	}
+}
+
+// orchestrationFiles returns the files to copy into the container for a
+// custom orchestration config.
+func orchestrationFiles(cfg *options) []testcontainers.ContainerFile {
+	if cfg.orchestrationFile == "" {
+		return nil
+	}
+
+	dst := path.Join(containerDETPath, ".evergreen", "orchestration", "configs",
+		orchestrationConfigDirs[cfg.topology], filepath.Base(cfg.orchestrationFile))
+
+	return []testcontainers.ContainerFile{{
+		HostFilePath:      cfg.orchestrationFile,
+		ContainerFilePath: dst,
+		FileMode:          0o644,
+	}}
+}
@@ -0,0 +1,196 @@
+package det
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// orchestrationPort is the port the mongo-orchestration REST API listens on
+// inside the container.
+const orchestrationPort = 8889
+
+// ReplicaSetID is the mongo-orchestration ID of the replica set started by
+// the DET replica_set configs.
+const ReplicaSetID = "repl0"
+
+// OrchestrationClient is a client for the mongo-orchestration REST API that
+// manages a det deployment. It lets tests reconfigure the topology at runtime
+// (add or remove members, restart nodes, step down the primary) instead of
+// tearing the whole cluster down.
+type OrchestrationClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOrchestrationClient returns a client for the REST API at baseURL, e.g.
+// "http://localhost:8889". Most tests should use Env.Orchestration instead.
+func NewOrchestrationClient(baseURL string) *OrchestrationClient {
+	return &OrchestrationClient{baseURL: baseURL + "/v1", httpClient: http.DefaultClient}
+}
+
+// Member is a replica set member as reported by mongo-orchestration.
+type Member struct {
+	ID       int    `json:"_id"`
+	Host     string `json:"host"`
+	ServerID string `json:"server_id"`
+	State    int    `json:"state"`
+}
+
+// ServerInfo describes a single mongod or mongos process.
+type ServerInfo struct {
+	ID         string `json:"id"`
+	MongoDBURI string `json:"mongodb_uri"`
+	ProcInfo   struct {
+		Alive bool `json:"alive"`
+		PID   int  `json:"pid"`
+	} `json:"procInfo"`
+}
+
+// ServerAction is an action mongo-orchestration can apply to a server.
+type ServerAction string
+
+// Supported server actions.
+const (
+	ServerStart    ServerAction = "start"
+	ServerStop     ServerAction = "stop"
+	ServerRestart  ServerAction = "restart"
+	ServerFreeze   ServerAction = "freeze"
+	ServerStepDown ServerAction = "stepdown"
+)
+
+// Members returns the members of the replica set rsID.
+func (c *OrchestrationClient) Members(ctx context.Context, rsID string) ([]Member, error) {
+	var members []Member
+	if err := c.do(ctx, http.MethodGet, "/replica_sets/"+url.PathEscape(rsID)+"/members", nil, &members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// Primary returns the current primary of the replica set rsID.
+func (c *OrchestrationClient) Primary(ctx context.Context, rsID string) (*Member, error) {
+	var primary Member
+	if err := c.do(ctx, http.MethodGet, "/replica_sets/"+url.PathEscape(rsID)+"/primary", nil, &primary); err != nil {
+		return nil, err
+	}
+
+	return &primary, nil
+}
+
+// AddMember adds a member to the replica set rsID. config is a
+// mongo-orchestration member document, e.g.
+//
+//	map[string]any{"rsParams": map[string]any{"priority": 0}}
+func (c *OrchestrationClient) AddMember(ctx context.Context, rsID string, config any) (*Member, error) {
+	var member Member
+	if err := c.do(ctx, http.MethodPost, "/replica_sets/"+url.PathEscape(rsID)+"/members", config, &member); err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// RemoveMember removes the member with the given _id from the replica set
+// rsID and shuts it down.
+func (c *OrchestrationClient) RemoveMember(ctx context.Context, rsID string, memberID int) error {
+	path := "/replica_sets/" + url.PathEscape(rsID) + "/members/" + strconv.Itoa(memberID)
+
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// StepDown asks the primary of the replica set rsID to step down.
+func (c *OrchestrationClient) StepDown(ctx context.Context, rsID string) error {
+	body := map[string]any{"action": ServerStepDown}
+
+	return c.do(ctx, http.MethodPost, "/replica_sets/"+url.PathEscape(rsID)+"/primary", body, nil)
+}
+
+// Server returns information about the server serverID.
+func (c *OrchestrationClient) Server(ctx context.Context, serverID string) (*ServerInfo, error) {
+	var info ServerInfo
+	if err := c.do(ctx, http.MethodGet, "/servers/"+url.PathEscape(serverID), nil, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// ServerAction applies action to the server serverID. Freeze and stepdown
+// take a timeout in seconds; it is ignored by the other actions.
+func (c *OrchestrationClient) ServerAction(ctx context.Context, serverID string, action ServerAction, timeoutSecs int) error {
+	body := map[string]any{"action": action}
+	if timeoutSecs > 0 {
+		body["timeout"] = timeoutSecs
+	}
+
+	return c.do(ctx, http.MethodPost, "/servers/"+url.PathEscape(serverID), body, nil)
+}
+
+// orchestrationBaseURL returns the host-reachable URL of the REST API, which
+// is published on a random port on the bridge network.
+func orchestrationBaseURL(ctx context.Context, container testcontainers.Container, cfg *options) (string, error) {
+	if !cfg.bridgeNetwork {
+		return "http://localhost:" + strconv.Itoa(orchestrationPort), nil
+	}
+
+	port, err := container.MappedPort(ctx, nat.Port(strconv.Itoa(orchestrationPort)+"/tcp"))
+	if err != nil {
+		return "", fmt.Errorf("get orchestration port: %w", err)
+	}
+
+	return "http://localhost:" + port.Port(), nil
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response
+// into out, if non-nil.
+func (c *OrchestrationClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode %s %s request: %w", method, path, err)
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package det
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// socks5Port is the port DET's SOCKS5 proxy listens on inside the container.
+const socks5Port = 1080
+
+// Credentials required by the proxy when WithSocks5Proxy(true) is used. They
+// match the ones used by the drivers' SOCKS5 spec tests.
+const (
+	Socks5Username = "username"
+	Socks5Password = "p4ssw0rd"
+)
+
+const socks5ReadyTimeout = 30 * time.Second
+
+// WithSocks5Proxy starts DET's SOCKS5 proxy (socks5srv.py) in the container
+// once the deployment is up, requiring Socks5Username and Socks5Password if
+// auth is set. The returned client connects directly; use Env.Socks5Proxy to
+// build clients that go through the proxy.
+func WithSocks5Proxy(auth bool) Option {
+	return func(o *options) {
+		o.socks5 = true
+		o.socks5Auth = auth
+	}
+}
+
+// Socks5Proxy describes how to reach the deployment through the SOCKS5 proxy.
+type Socks5Proxy struct {
+	Host     string
+	Port     int
+	Username string // empty unless the proxy requires auth
+	Password string
+}
+
+// Query returns the proxyHost, proxyPort, and (if required) proxyUsername and
+// proxyPassword URI options.
+func (p *Socks5Proxy) Query() url.Values {
+	query := url.Values{
+		"proxyHost": {p.Host},
+		"proxyPort": {strconv.Itoa(p.Port)},
+	}
+
+	if p.Username != "" {
+		query.Set("proxyUsername", p.Username)
+		query.Set("proxyPassword", p.Password)
+	}
+
+	return query
+}
+
+// Socks5Proxy returns the proxy settings, or nil if WithSocks5Proxy was not
+// used.
+func (e *Env) Socks5Proxy() *Socks5Proxy {
+	return e.socks5
+}
+
+// startSocks5Proxy runs socks5srv.py in the background and waits until it
+// accepts connections from the host.
+func startSocks5Proxy(ctx context.Context, container testcontainers.Container, cfg *options) (*Socks5Proxy, error) {
+	proxy := &Socks5Proxy{Host: "localhost", Port: socks5Port}
+
+	args := fmt.Sprintf("--port %d", socks5Port)
+	if cfg.socks5Auth {
+		proxy.Username = Socks5Username
+		proxy.Password = Socks5Password
+		args += fmt.Sprintf(" --auth %s:%s", Socks5Username, Socks5Password)
+	}
+
+	script := fmt.Sprintf("nohup python3 %s %s > /tmp/socks5.log 2>&1 &",
+		path.Join(containerDETPath, ".evergreen", "socks5srv.py"), args)
+
+	if err := execScript(ctx, container, script); err != nil {
+		return nil, err
+	}
+
+	// On the bridge network the proxy is published on a random host port.
+	if cfg.bridgeNetwork {
+		port, err := container.MappedPort(ctx, nat.Port(strconv.Itoa(socks5Port)+"/tcp"))
+		if err != nil {
+			return nil, fmt.Errorf("get proxy port: %w", err)
+		}
+
+		proxy.Port = port.Int()
+	}
+
+	addr := proxy.Host + ":" + strconv.Itoa(proxy.Port)
+	if err := awaitListening(ctx, addr, socks5ReadyTimeout); err != nil {
+		return nil, err
+	}
+
+	return proxy, nil
+}
@@ -0,0 +1,110 @@
+package det
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	// containerDETPath is where the DET Dockerfile places the repository.
+	containerDETPath = "/root/drivers-evergreen-tools"
+
+	// x509Dir is the DET directory holding the certificates used by the ssl
+	// orchestration configs.
+	x509Dir = ".evergreen/x509gen"
+)
+
+// WithSSL runs the orchestration with SSL=ssl. The DET x509 certificate set
+// is copied out of the container and used to build the client's TLS config,
+// which is also available from Env.TLSConfig.
+func WithSSL() Option {
+	return func(o *options) {
+		o.ssl = true
+	}
+}
+
+func sslMode(cfg *options) string {
+	if usesTLS(cfg) {
+		return "ssl"
+	}
+
+	return "nossl"
+}
+
+// usesTLS reports whether the deployment requires TLS connections.
+func usesTLS(cfg *options) bool {
+	return cfg.ssl || cfg.ocspMode != ""
+}
+
+// copyX509Certs copies the CA and client certificates from the container
+// into dir and returns their host paths.
+func copyX509Certs(ctx context.Context, container testcontainers.Container, dir string) (caFile, clientFile string, err error) {
+	copyFile := func(name string) (string, error) {
+		r, err := container.CopyFileFromContainer(ctx, path.Join(containerDETPath, x509Dir, name))
+		if err != nil {
+			return "", fmt.Errorf("copy %s from container: %w", name, err)
+		}
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", name, err)
+		}
+
+		dst := filepath.Join(dir, name)
+		if err := os.WriteFile(dst, data, 0o600); err != nil {
+			return "", fmt.Errorf("write %s: %w", name, err)
+		}
+
+		return dst, nil
+	}
+
+	if caFile, err = copyFile("ca.pem"); err != nil {
+		return "", "", err
+	}
+
+	if clientFile, err = copyFile("client.pem"); err != nil {
+		return "", "", err
+	}
+
+	return caFile, clientFile, nil
+}
+
+// newTLSConfig builds a client TLS config trusting caFile and presenting the
+// certificate and key bundled in clientFile.
+func newTLSConfig(caFile, clientFile string) (*tls.Config, error) {
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("no certificates found in CA file")
+	}
+
+	clientPEM, err := os.ReadFile(clientFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client certificate: %w", err)
+	}
+
+	// DET's client.pem holds both the certificate and its private key.
+	cert, err := tls.X509KeyPair(clientPEM, clientPEM)
+	if err != nil {
+		return nil, fmt.Errorf("parse client certificate: %w", err)
+	}
+
+	return &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{cert},
+	}, nil
+}
@@ -0,0 +1,102 @@
+package det
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// upgradeTimeout bounds each step of UpgradeTo: downloading the binaries and
+// bringing each member back.
+const upgradeTimeout = 5 * time.Minute
+
+// UpgradeTo performs a rolling binary upgrade of the replica set to version
+// (e.g. "8.0"). The new binaries are downloaded and moved over the installed
+// ones, which running members keep using until restarted. Each secondary and
+// arbiter is then restarted, and finally the primary is stepped down and restarted. The
+// featureCompatibilityVersion is left unchanged, so the set runs in
+// mixed-version mode until the test bumps it.
+func (e *Env) UpgradeTo(t *testing.T, version string) {
+	t.Helper()
+
+	require.NotNil(t, e.container, "UpgradeTo requires a DET container")
+
+	ctx := t.Context()
+
+	require.NoError(t, e.installBinaries(ctx, version), "failed to install MongoDB %s", version)
+
+	members, err := e.orchestration.Members(ctx, ReplicaSetID)
+	require.NoError(t, err, "failed to list members")
+
+	var primary *Member
+	for _, m := range members {
+		if m.State == statePrimary {
+			primary = &m
+			continue
+		}
+
+		// Arbiters come back as arbiters; every other member, including
+		// one still recovering, should come back as a secondary.
+		want := stateSecondary
+		if m.State == stateArbiter {
+			want = stateArbiter
+		}
+
+		require.NoError(t, e.restartAndAwait(ctx, m, want), "failed to upgrade member %s", m.Host)
+	}
+
+	require.NotNil(t, primary, "no primary to upgrade")
+
+	require.NoError(t, e.orchestration.StepDown(ctx, ReplicaSetID), "failed to step down primary %s", primary.Host)
+
+	electCtx, cancel := context.WithTimeout(ctx, electionTimeout)
+	defer cancel()
+
+	require.NoError(t, e.awaitNewPrimary(electCtx, primary.Host), "no new primary elected")
+	require.NoError(t, e.restartAndAwait(ctx, *primary, stateSecondary), "failed to upgrade member %s", primary.Host)
+}
+
+// installBinaries downloads version with DET's mongodl.py and moves the
+// binaries over the installed ones. Renaming keeps running processes on the
+// old executables.
+func (e *Env) installBinaries(ctx context.Context, version string) error {
+	ctx, cancel := context.WithTimeout(ctx, upgradeTimeout)
+	defer cancel()
+
+	staging := "/tmp/mongodb-" + version
+	binDir := path.Join(containerDETPath, "mongodb", "bin")
+
+	script := fmt.Sprintf(
+		"python3 %s --component archive --version %s --edition enterprise --out %s --strip-path-components 2 --only '**/bin/*'"+
+			" && for f in %s/*; do mv -f \"$f\" %s/; done",
+		path.Join(containerDETPath, ".evergreen", "mongodl.py"), version, staging, staging, binDir)
+
+	return execScript(ctx, e.container, script)
+}
+
+// restartAndAwait restarts member and waits until it reports state again.
+func (e *Env) restartAndAwait(ctx context.Context, member Member, state int) error {
+	ctx, cancel := context.WithTimeout(ctx, upgradeTimeout)
+	defer cancel()
+
+	if err := e.orchestration.ServerAction(ctx, member.ServerID, ServerRestart, 0); err != nil {
+		return err
+	}
+
+	for {
+		m, err := e.member(ctx, member.Host)
+		if err == nil && m.State == state {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s did not reach state %d: %w (last error: %v)", member.Host, state, ctx.Err(), err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import "go.mongodb.org/mongo-driver/v2/mongo/options"
+
+// WithAppName returns a copy of fp that only affects connections whose
+// handshake carried appName, so other clients sharing the server are left
+// alone. It applies to failCommand fail points and panics for any other
+// payload.
+func (fp FailPoint) WithAppName(appName string) FailPoint {
+	return fp.withData("appName", func(d *Data) {
+		d.AppName = appName
+	})
+}
+
+// ForApp sets appName on opts and returns fp scoped to it, so fp only affects
+// clients created from opts:
+//
+//	opts := options.Client().ApplyURI(uri)
+//	fp := failpoint.ForApp(opts, t.Name(), failpoint.NewSingleErr("find", 91))
+//	client, err := mongo.Connect(opts)
+//	...
+//	failpoint.Enable(t, client, fp)
+func ForApp(opts *options.ClientOptions, appName string, fp FailPoint) FailPoint {
+	opts.SetAppName(appName)
+
+	return fp.WithAppName(appName)
+}
+
+// heartbeatCommands are the handshake and monitoring commands, across server
+// versions.
+var heartbeatCommands = []string{"hello", "isMaster"}
+
+// NewHeartbeatErr creates a FailPoint that will cause hello and isMaster to
+// fail `times` times with errCode, but only for connections of appName, so
+// the monitoring connections of other clients sharing the server keep
+// working. It panics if appName is empty, since that would fail every
+// client's heartbeats.
+func NewHeartbeatErr(appName string, errCode, times int32) FailPoint {
+	return newHeartbeat(appName, times, Data{ErrorCode: errCode})
+}
+
+// NewHeartbeatNetworkErr is NewHeartbeatErr closing the connection instead of
+// returning an error.
+func NewHeartbeatNetworkErr(appName string, times int32) FailPoint {
+	return newHeartbeat(appName, times, Data{CloseConnection: true})
+}
+
+// NewHeartbeatBlock is NewHeartbeatErr blocking hello and isMaster for
+// blockTimeMS instead of failing them, e.g. to make the monitor observe a
+// timeout.
+func NewHeartbeatBlock(appName string, blockTimeMS, times int32) FailPoint {
+	return newHeartbeat(appName, times, Data{BlockConnection: true, BlockTimeMS: blockTimeMS})
+}
+
+func newHeartbeat(appName string, times int32, data Data) FailPoint {
+	if appName == "" {
+		panic("failpoint: heartbeat fail points require an appName")
+	}
+
+	data.FailCommands = append([]string(nil), heartbeatCommands...)
+	data.AppName = appName
+
+	return FailPoint{
+		ConfigureFailPoint: "failCommand",
+		Mode:               Mode{Times: times},
+		Data:               data,
+	}
+}
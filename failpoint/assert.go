@@ -0,0 +1,50 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	mongov1 "go.mongodb.org/mongo-driver/mongo"
+)
+
+// RequireErrorCode fails the test unless err carries the server error code
+// code. It sees through mongo.CommandError, mongo.WriteException, and
+// mongo.BulkWriteException (and their v1 counterparts) via mongo.ServerError,
+// including wrapped errors.
+func RequireErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+
+	require.Error(t, err, "expected error with code %d", code)
+
+	var srvErr mongo.ServerError
+	if errors.As(err, &srvErr) {
+		require.True(t, srvErr.HasErrorCode(code), "expected error code %d, got %v: %v", code, srvErr.ErrorCodes(), err)
+		return
+	}
+
+	var srvErrV1 mongov1.ServerError
+	if errors.As(err, &srvErrV1) {
+		require.True(t, srvErrV1.HasErrorCode(code), "expected error code %d, got %v", code, err)
+		return
+	}
+
+	require.Failf(t, "expected server error", "expected error code %d, got %T: %v", code, err, err)
+}
+
+// RequireTimeout fails the test unless err is a timeout, whether a context
+// deadline, a client-side CSOT timeout, or a server MaxTimeMSExpired error.
+func RequireTimeout(t *testing.T, err error) {
+	t.Helper()
+
+	require.Error(t, err, "expected timeout error")
+	require.True(t, mongo.IsTimeout(err) || mongov1.IsTimeout(err), "expected timeout error, got %T: %v", err, err)
+}
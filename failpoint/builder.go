@@ -0,0 +1,60 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import "fmt"
+
+// withData returns a copy of fp with its failCommand data modified by fn. It
+// panics if fp is not a failCommand fail point, since the With* builders have
+// no meaning for other payloads.
+func (fp FailPoint) withData(option string, fn func(*Data)) FailPoint {
+	data, ok := fp.Data.(Data)
+	if !ok {
+		panic(fmt.Sprintf("failpoint: %s does not support %s (data is %T)", fp.ConfigureFailPoint, option, fp.Data))
+	}
+
+	// Don't share the command list with the original.
+	data.FailCommands = append([]string(nil), data.FailCommands...)
+	fn(&data)
+	fp.Data = data
+
+	return fp
+}
+
+// WithBlock returns a copy of fp that blocks the connection for blockTimeMS
+// before responding. Combined with an error constructor it makes slow
+// failures rather than slow successes:
+//
+//	failpoint.NewSingleErr("find", 262).WithBlock(600)
+func (fp FailPoint) WithBlock(blockTimeMS int32) FailPoint {
+	return fp.withData("blockConnection", func(d *Data) {
+		d.BlockConnection = true
+		d.BlockTimeMS = blockTimeMS
+	})
+}
+
+// WithErrorLabels returns a copy of fp whose failures carry labels.
+func (fp FailPoint) WithErrorLabels(labels ...string) FailPoint {
+	return fp.withData("errorLabels", func(d *Data) {
+		d.ErrorLabels = &labels
+	})
+}
+
+// NewBlockThenErr creates a FailPoint that will cause the specified command to
+// block for blockTimeMS and then fail with errCode, `times` times.
+func NewBlockThenErr(cmdName string, blockTimeMS, errCode, times int32) FailPoint {
+	return FailPoint{
+		ConfigureFailPoint: "failCommand",
+		Mode:               Mode{Times: times},
+		Data: Data{
+			FailCommands:    []string{cmdName},
+			ErrorCode:       errCode,
+			BlockConnection: true,
+			BlockTimeMS:     blockTimeMS,
+		},
+	}
+}
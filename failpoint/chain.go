@@ -0,0 +1,95 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver"
+)
+
+// EnableAfterCommandFailure returns a command monitor that sets next through
+// client the first time triggerCmd fails with triggerCode. Install the
+// monitor on the client under test (e.g. with options.Client().SetMonitor)
+// to chain fail points mid-operation, as the retryable writes spec tests do:
+// the first fail point fails the attempt, and next is in place before the
+// driver retries.
+//
+// next is enabled from the command monitor callback, so errors enabling it
+// are reported with t.Errorf. It is disabled when the test ends.
+//
+// The monitor is a v2 event.CommandMonitor, so it only observes v2 clients;
+// client, which sets next, may be of either version.
+//
+// The monitor has to be installed before the client under test connects, but
+// next is usually set through that same client. Install a monitor.Recorder
+// instead and chain the returned monitor once the client exists:
+//
+//	rec := monitor.NewRecorder()
+//	client, teardown := mongolocal.StartT(t, ctx, mongolocal.WithRecorder(rec))
+//	rec.Chain(failpoint.EnableAfterCommandFailure(t, client, "insert", codes.ReadConcernMajorityNotAvailableYet, next))
+func EnableAfterCommandFailure[C Client](t *testing.T, client C, triggerCmd string, triggerCode int, next FailPoint) *event.CommandMonitor {
+	t.Helper()
+
+	return EnableRunnerAfterCommandFailure(t, NewRunner(client), triggerCmd, triggerCode, next)
+}
+
+// EnableRunnerAfterCommandFailure sets next through r after a command failure.
+// See EnableAfterCommandFailure.
+func EnableRunnerAfterCommandFailure(t *testing.T, r Runner, triggerCmd string, triggerCode int, next FailPoint) *event.CommandMonitor {
+	t.Helper()
+
+	var (
+		once    sync.Once
+		mu      sync.Mutex
+		enabled bool
+	)
+
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !enabled {
+			return
+		}
+
+		if err := DisableRunner(context.Background(), r, next.ConfigureFailPoint); err != nil && !isClientDisconnected(err) {
+			t.Errorf("error disabling chained failpoint: %v", err)
+		}
+	})
+
+	return &event.CommandMonitor{
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			if evt.CommandName != triggerCmd {
+				return
+			}
+
+			// Command monitors see the driver's own error, not the
+			// mongo.ServerError operations return.
+			var drvErr driver.Error
+			if !errors.As(evt.Failure, &drvErr) || int(drvErr.Code) != triggerCode {
+				return
+			}
+
+			once.Do(func() {
+				mu.Lock()
+				defer mu.Unlock()
+
+				if _, err := r.RunAdminCommand(context.Background(), next); err != nil {
+					t.Errorf("error enabling chained failpoint %q: %v", next.ConfigureFailPoint, err)
+					return
+				}
+
+				enabled = true
+			})
+		},
+	}
+}
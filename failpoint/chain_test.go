@@ -0,0 +1,77 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/prestonvasquez/go-playground/failpoint/codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver"
+)
+
+// fakeRunner records the commands it runs.
+type fakeRunner struct {
+	mu   sync.Mutex
+	cmds []any
+}
+
+func (r *fakeRunner) RunAdminCommand(_ context.Context, cmd any) (bson.Raw, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cmds = append(r.cmds, cmd)
+
+	return bson.Raw{}, nil
+}
+
+func (r *fakeRunner) commands() []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]any(nil), r.cmds...)
+}
+
+func TestEnableRunnerAfterCommandFailure(t *testing.T) {
+	r := &fakeRunner{}
+	next := NewAlwaysOnErrWithLabels("insert", codes.NotWritablePrimary, []string{"RetryableWriteError"})
+
+	t.Run("enable", func(t *testing.T) {
+		cm := EnableRunnerAfterCommandFailure(t, r, "insert", codes.ReadConcernMajorityNotAvailableYet, next)
+
+		fail := func(cmdName string, err error) {
+			cm.Failed(context.Background(), &event.CommandFailedEvent{
+				CommandFinishedEvent: event.CommandFinishedEvent{CommandName: cmdName},
+				Failure:              err,
+			})
+		}
+
+		// Other commands, other codes, and non-server errors don't trigger.
+		fail("find", driver.Error{Code: codes.ReadConcernMajorityNotAvailableYet})
+		fail("insert", driver.Error{Code: codes.NotWritablePrimary})
+		fail("insert", errors.New("network error"))
+		assert.Empty(t, r.commands())
+
+		fail("insert", driver.Error{Code: codes.ReadConcernMajorityNotAvailableYet})
+		fail("insert", driver.Error{Code: codes.ReadConcernMajorityNotAvailableYet})
+
+		cmds := r.commands()
+		require.Len(t, cmds, 1, "next must be enabled once")
+		assert.Equal(t, next, cmds[0])
+	})
+
+	// The subtest's cleanup disabled next.
+	cmds := r.commands()
+	require.Len(t, cmds, 2)
+	assert.Equal(t, FailPoint{ConfigureFailPoint: next.ConfigureFailPoint, Mode: ModeOff}, cmds[1])
+}
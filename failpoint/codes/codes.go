@@ -0,0 +1,76 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+// Package codes catalogs the server error codes tests commonly fail commands
+// with. The constants are untyped, so they can be passed to the failpoint
+// constructors (int32) and to HasErrorCode and failpoint.RequireErrorCode
+// (int) alike.
+//
+// For the full list, see
+// https://github.com/mongodb/mongo/blob/master/src/mongo/base/error_codes.yml
+package codes
+
+// General errors.
+const (
+	InternalError                      = 1
+	BadValue                           = 2
+	UnknownError                       = 8
+	Unauthorized                       = 13
+	IllegalOperation                   = 20
+	LockTimeout                        = 24
+	NamespaceNotFound                  = 26
+	CursorNotFound                     = 43
+	CommandNotFound                    = 59
+	WriteConflict                      = 112
+	DuplicateKey                       = 11000
+	Interrupted                        = 11601
+	OperationNotSupportedInTransaction = 263
+	NoSuchTransaction                  = 251
+)
+
+// Network and timeout errors.
+const (
+	HostUnreachable                   = 6
+	HostNotFound                      = 7
+	MaxTimeMSExpired                  = 50
+	NetworkTimeout                    = 89
+	NetworkInterfaceExceededTimeLimit = 202
+	ExceededTimeLimit                 = 262
+	SocketException                   = 9001
+)
+
+// Replica set state errors. Most are retryable and clear the server's pool
+// or mark it unknown.
+const (
+	ShutdownInProgress                 = 91
+	FailedToSatisfyReadPreference      = 133
+	ReadConcernMajorityNotAvailableYet = 134
+	PrimarySteppedDown                 = 189
+	NotWritablePrimary                 = 10107
+	InterruptedAtShutdown              = 11600
+	InterruptedDueToReplStateChange    = 11602
+	NotPrimaryNoSecondaryOk            = 13435
+	NotPrimaryOrSecondary              = 13436
+	StaleConfig                        = 13388
+)
+
+// Write concern errors.
+const (
+	WriteConcernFailed        = 64
+	UnknownReplWriteConcern   = 79
+	UnsatisfiableWriteConcern = 100
+)
+
+// Change stream errors.
+const (
+	CappedPositionLost      = 136
+	RetryChangeStream       = 234
+	ChangeStreamFatalError  = 280
+	ChangeStreamHistoryLost = 286
+)
+
+// SystemOverloaded is the overload error of the client backpressure spec.
+const SystemOverloaded = 462
@@ -0,0 +1,47 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+// NewFailGetMoreAfterCursorCheckout creates a failGetMoreAfterCursorCheckout
+// FailPoint that fails getMore `times` times with errCode after the server
+// has checked out the cursor, so the failure happens mid-iteration rather
+// than at command dispatch. It is the server-side trigger change stream
+// resume tests rely on.
+func NewFailGetMoreAfterCursorCheckout(errCode, times int32) FailPoint {
+	return FailPoint{
+		ConfigureFailPoint: FailGetMoreAfterCursorCheckout,
+		Mode:               Mode{Times: times},
+		Data:               FailGetMoreAfterCursorCheckoutData{ErrorCode: errCode},
+	}
+}
+
+// NewGetMoreErr creates a FailPoint that will cause getMore to fail `times`
+// times with errCode.
+func NewGetMoreErr(errCode, times int32) FailPoint {
+	return FailPoint{
+		ConfigureFailPoint: "failCommand",
+		Mode:               Mode{Times: times},
+		Data: Data{
+			FailCommands: []string{"getMore"},
+			ErrorCode:    errCode,
+		},
+	}
+}
+
+// NewKillCursorsErr creates a FailPoint that will cause killCursors to fail
+// `times` times with errCode, leaving the cursor open on the server. Use it
+// to check cursors are not leaked when closing them fails.
+func NewKillCursorsErr(errCode, times int32) FailPoint {
+	return FailPoint{
+		ConfigureFailPoint: "failCommand",
+		Mode:               Mode{Times: times},
+		Data: Data{
+			FailCommands: []string{"killCursors"},
+			ErrorCode:    errCode,
+		},
+	}
+}
@@ -8,9 +8,12 @@ package failpoint
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 
+	"github.com/prestonvasquez/go-playground/failpoint/codes"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -27,6 +30,25 @@ const (
 	ModeOff = "off"
 )
 
+// Names of the server fail points this package has typed payloads for.
+const (
+	// FailCommand fails or blocks commands; configure it with Data.
+	FailCommand = "failCommand"
+
+	// HangAfterCollectionInserts blocks inserts into a collection after the
+	// documents are written; configure it with HangAfterCollectionInsertsData.
+	HangAfterCollectionInserts = "hangAfterCollectionInserts"
+
+	// MaxTimeAlwaysTimeOut makes every operation with a maxTimeMS fail with
+	// MaxTimeMSExpired. It takes no data.
+	MaxTimeAlwaysTimeOut = "maxTimeAlwaysTimeOut"
+
+	// FailGetMoreAfterCursorCheckout fails getMore commands once the cursor
+	// has been checked out; configure it with
+	// FailGetMoreAfterCursorCheckoutData.
+	FailGetMoreAfterCursorCheckout = "failGetMoreAfterCursorCheckout"
+)
+
 // FailPoint is used to configure a server fail point. It is intended to be
 // passed as the command argument to RunCommand.
 //
@@ -35,8 +57,11 @@ const (
 type FailPoint struct {
 	ConfigureFailPoint string `bson:"configureFailPoint"`
 	// Mode should be a string, FailPointMode, or map[string]any
-	Mode any  `bson:"mode"`
-	Data Data `bson:"data"`
+	Mode any `bson:"mode"`
+	// Data is the fail point's payload: Data for failCommand, or the typed
+	// payload of another fail point (e.g. HangAfterCollectionInsertsData).
+	// It may be nil for fail points that take no data.
+	Data any `bson:"data,omitempty"`
 }
 
 // Mode configures when a fail point will be enabled. It is used to set the
@@ -46,8 +71,8 @@ type Mode struct {
 	Skip  int32 `bson:"skip"`
 }
 
-// Data configures how a fail point will behave. It is used to set the
-// FailPoint.Data field.
+// Data configures how a failCommand fail point will behave. It is used to set
+// the FailPoint.Data field.
 type Data struct {
 	FailCommands                  []string           `bson:"failCommands,omitempty"`
 	CloseConnection               bool               `bson:"closeConnection,omitempty"`
@@ -60,6 +85,25 @@ type Data struct {
 	AppName                       string             `bson:"appName,omitempty"`
 }
 
+// HangAfterCollectionInsertsData configures the hangAfterCollectionInserts fail
+// point. It is used to set the FailPoint.Data field.
+type HangAfterCollectionInsertsData struct {
+	// CollectionNS is the "db.collection" namespace whose inserts hang.
+	CollectionNS string `bson:"collectionNS,omitempty"`
+	// FirstID restricts the fail point to inserts whose first document has
+	// this _id.
+	FirstID any `bson:"first_id,omitempty"`
+}
+
+// FailGetMoreAfterCursorCheckoutData configures the
+// failGetMoreAfterCursorCheckout fail point. It is used to set the
+// FailPoint.Data field.
+type FailGetMoreAfterCursorCheckoutData struct {
+	// ErrorCode is the code getMore fails with; the server picks one if it
+	// is zero.
+	ErrorCode int32 `bson:"errorCode,omitempty"`
+}
+
 // WriteConcernError is the write concern error to return when the fail point is
 // triggered. It is used to set the FailPoint.Data.WriteConcernError field.
 type WriteConcernError struct {
@@ -72,48 +116,114 @@ type WriteConcernError struct {
 
 type TeardownFunc func(t *testing.T)
 
+// DisableFunc turns off a fail point set by EnableCtx, or restores the
+// configuration it replaced with WithRestorePrevious. Only the first call
+// has an effect; later calls return its result.
+type DisableFunc func(ctx context.Context) error
+
+// Runner runs a command against the admin database. It abstracts over the
+// client types of the two driver majors; see NewRunner.
+type Runner interface {
+	RunAdminCommand(ctx context.Context, cmd any) (bson.Raw, error)
+}
+
+// Client is the set of client types fail points can be configured through.
+type Client interface {
+	*mongo.Client | *mongov1.Client
+}
+
+type runnerV2 struct{ client *mongo.Client }
+
+func (r runnerV2) RunAdminCommand(ctx context.Context, cmd any) (bson.Raw, error) {
+	return r.client.Database("admin").RunCommand(ctx, cmd).Raw()
+}
+
+type runnerV1 struct{ client *mongov1.Client }
+
+func (r runnerV1) RunAdminCommand(ctx context.Context, cmd any) (bson.Raw, error) {
+	raw, err := r.client.Database("admin").RunCommand(ctx, cmd).DecodeBytes()
+
+	return bson.Raw(raw), err
+}
+
+// NewRunner returns a Runner for a v1 or v2 client.
+func NewRunner[C Client](client C) Runner {
+	switch c := any(client).(type) {
+	case *mongov1.Client:
+		return runnerV1{client: c}
+	case *mongo.Client:
+		return runnerV2{client: c}
+	}
+
+	// Unreachable: the Client constraint only admits the two types above.
+	panic(fmt.Sprintf("failpoint: unsupported client type %T", client))
+}
+
 // Enable sets a fail point for the client associated with T. Commands to
 // create the failpoint will appear in command monitoring channels. The fail
-// point will automatically be disabled after this test has run.
-func Enable(t *testing.T, client *mongo.Client, fp FailPoint) TeardownFunc {
+// point will automatically be disabled after this test has run; call the
+// returned TeardownFunc to disable it earlier.
+//
+// Enabling a fail point that is already enabled through the same client
+// replaces its configuration; Enable logs when that happens. Pass
+// WithRestorePrevious to bring the replaced configuration back on teardown.
+func Enable[C Client](t *testing.T, client C, fp FailPoint, opts ...EnableOption) TeardownFunc {
 	t.Helper()
 
-	if modeMap, ok := fp.Mode.(map[string]any); ok {
-		var key string
-		var err error
+	return EnableRunner(t, NewRunner(client), fp, opts...)
+}
 
-		if times, ok := modeMap["times"]; ok {
-			key = "times"
-			modeMap["times"], err = interfaceToInt32(times)
-		}
-		if skip, ok := modeMap["skip"]; ok {
-			key = "skip"
-			modeMap["skip"], err = interfaceToInt32(skip)
-		}
+// EnableV1 is Enable for a v1 client.
+//
+// Deprecated: Enable accepts v1 clients directly.
+func EnableV1(t *testing.T, client *mongov1.Client, fp FailPoint) TeardownFunc {
+	t.Helper()
 
-		require.NoError(t, err, "failed to convert failpoint mode %q to int32", key)
+	return Enable(t, client, fp)
+}
+
+// EnableRunner sets a fail point through r. See Enable.
+func EnableRunner(t *testing.T, r Runner, fp FailPoint, opts ...EnableOption) TeardownFunc {
+	t.Helper()
+
+	var cfg enableOptions
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
-	admin := client.Database("admin")
-	require.NoError(t, admin.RunCommand(context.Background(), fp).Err(), "error enabling failpoint")
+	disable, replaced, err := enable(context.Background(), r, fp, cfg)
+	require.NoError(t, err, "error enabling failpoint")
 
-	return func(t *testing.T) {
-		db := client.Database("admin")
-		cmd := FailPoint{
-			ConfigureFailPoint: fp.ConfigureFailPoint,
-			Mode:               ModeOff,
+	if replaced && !cfg.restorePrevious {
+		t.Logf("fail point %q was already enabled through this client; its configuration was replaced", fp.ConfigureFailPoint)
+	}
+
+	// Tests that forget the teardown would otherwise leave the fail point set
+	// on a shared server. By the time cleanups run the test may have
+	// disconnected the client, in which case there is nothing left to do it
+	// with.
+	t.Cleanup(func() {
+		if err := disable(context.Background()); err != nil {
+			if isClientDisconnected(err) {
+				t.Logf("fail point %q left enabled: client disconnected before cleanup", fp.ConfigureFailPoint)
+				return
+			}
+
+			t.Errorf("error disabling failpoint: %v", err)
 		}
+	})
 
-		require.NoError(t, db.RunCommand(context.Background(), cmd).Err())
+	return func(t *testing.T) {
+		require.NoError(t, disable(context.Background()))
 	}
 }
 
-func EnableV1(t *testing.T, client *mongov1.Client, fp FailPoint) TeardownFunc {
-	t.Helper()
-
+// enable sets fp through r. The returned DisableFunc turns it off, or
+// restores the configuration it replaced per cfg. replaced reports whether fp
+// replaced a configuration enabled through r.
+func enable(ctx context.Context, r Runner, fp FailPoint, cfg enableOptions) (disable DisableFunc, replaced bool, err error) {
 	if modeMap, ok := fp.Mode.(map[string]any); ok {
 		var key string
-		var err error
 
 		if times, ok := modeMap["times"]; ok {
 			key = "times"
@@ -124,21 +234,84 @@ func EnableV1(t *testing.T, client *mongov1.Client, fp FailPoint) TeardownFunc {
 			modeMap["skip"], err = interfaceToInt32(skip)
 		}
 
-		require.NoError(t, err, "failed to convert failpoint mode %q to int32", key)
+		if err != nil {
+			return nil, false, fmt.Errorf("convert failpoint mode %q to int32: %w", key, err)
+		}
 	}
 
-	admin := client.Database("admin")
-	require.NoError(t, admin.RunCommand(context.Background(), fp).Err(), "error enabling failpoint")
+	if _, err := r.RunAdminCommand(ctx, fp); err != nil {
+		return nil, false, fmt.Errorf("enable fail point %q: %w", fp.ConfigureFailPoint, err)
+	}
 
-	return func(t *testing.T) {
-		db := client.Database("admin")
-		cmd := FailPoint{
-			ConfigureFailPoint: fp.ConfigureFailPoint,
-			Mode:               ModeOff,
-		}
+	entry, prev := track(r, fp)
+
+	var (
+		once       sync.Once
+		disableErr error
+	)
+
+	disable = func(ctx context.Context) error {
+		once.Do(func() {
+			top := untrack(r, entry)
+			if cfg.restorePrevious && top != nil {
+				if _, err := r.RunAdminCommand(ctx, *top); err != nil {
+					disableErr = fmt.Errorf("restore fail point %q: %w", fp.ConfigureFailPoint, err)
+				}
+
+				return
+			}
 
-		require.NoError(t, db.RunCommand(context.Background(), cmd).Err())
+			disableErr = DisableRunner(ctx, r, fp.ConfigureFailPoint)
+		})
+
+		return disableErr
 	}
+
+	return disable, prev != nil, nil
+}
+
+// EnableCtx sets a fail point through client like Enable, but without a
+// *testing.T, for benchmarks, example programs, and tools. The caller must
+// call the returned DisableFunc; nothing disables the fail point otherwise.
+func EnableCtx[C Client](ctx context.Context, client C, fp FailPoint, opts ...EnableOption) (DisableFunc, error) {
+	return EnableRunnerCtx(ctx, NewRunner(client), fp, opts...)
+}
+
+// EnableRunnerCtx sets a fail point through r. See EnableCtx.
+func EnableRunnerCtx(ctx context.Context, r Runner, fp FailPoint, opts ...EnableOption) (DisableFunc, error) {
+	var cfg enableOptions
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	disable, _, err := enable(ctx, r, fp, cfg)
+
+	return disable, err
+}
+
+// Disable turns off the fail point name on the server client's command is
+// routed to. Use it for fail points set without Enable, e.g. through a raw
+// configureFailPoint command.
+func Disable[C Client](ctx context.Context, client C, name string) error {
+	return DisableRunner(ctx, NewRunner(client), name)
+}
+
+// DisableRunner turns off the fail point name through r. See Disable.
+func DisableRunner(ctx context.Context, r Runner, name string) error {
+	cmd := FailPoint{
+		ConfigureFailPoint: name,
+		Mode:               ModeOff,
+	}
+
+	if _, err := r.RunAdminCommand(ctx, cmd); err != nil {
+		return fmt.Errorf("disable fail point %q: %w", name, err)
+	}
+
+	return nil
+}
+
+func isClientDisconnected(err error) bool {
+	return errors.Is(err, mongo.ErrClientDisconnected) || errors.Is(err, mongov1.ErrClientDisconnected)
 }
 
 func interfaceToInt32(i any) (int32, error) {
@@ -190,20 +363,35 @@ func NewSingleErrWithLabels(cmdName string, errCode int32, errLabels []string) F
 // NewAlwaysOnErrWithLabels creates a FailPoint that will cause the specified
 // command to always fail with the given error code and error labels.
 func NewAlwaysOnErrWithLabels(cmdName string, errCode int32, errLabels []string) FailPoint {
-	fp := FailPoint{
+	data := Data{
+		FailCommands: []string{cmdName},
+		ErrorCode:    errCode,
+	}
+
+	if errLabels != nil {
+		data.ErrorLabels = &errLabels
+	}
+
+	return FailPoint{
 		ConfigureFailPoint: "failCommand",
 		Mode:               ModeAlwaysOn,
+		Data:               data,
+	}
+}
+
+// NewErrWithLabelsOnly creates a FailPoint that will cause the specified
+// command to fail once carrying the given error labels and no error code, for
+// driver logic that keys purely off labels (e.g. RetryableWriteError or
+// TransientTransactionError).
+func NewErrWithLabelsOnly(cmdName string, labels ...string) FailPoint {
+	return FailPoint{
+		ConfigureFailPoint: "failCommand",
+		Mode:               Mode{Times: 1},
 		Data: Data{
 			FailCommands: []string{cmdName},
-			ErrorCode:    errCode,
+			ErrorLabels:  &labels,
 		},
 	}
-
-	if errLabels != nil {
-		fp.Data.ErrorLabels = &errLabels
-	}
-
-	return fp
 }
 
 // NewAlwaysOnErr creates a FailPoint that will cause the specified command to
@@ -215,7 +403,7 @@ func NewAlwaysOnErr(cmdName string, errCode int32) FailPoint {
 // Backpressure-related fixtures from the client-backpressure spec
 // (https://github.com/mongodb/specifications/blob/master/source/client-backpressure/client-backpressure.md).
 const (
-	OverloadErrorCode     int32 = 462
+	OverloadErrorCode     int32 = codes.SystemOverloaded
 	SystemOverloadedLabel       = "SystemOverloadedError"
 	RetryableErrorLabel         = "RetryableError"
 )
@@ -267,3 +455,38 @@ func NewBlock(blockTimeMS int32, times int32, cmds ...string) FailPoint {
 		},
 	}
 }
+
+// NewNetworkErr creates a FailPoint that will cause the specified command to
+// close the connection `times` times, surfacing as a network error in the
+// driver.
+func NewNetworkErr(cmdName string, times int32) FailPoint {
+	return FailPoint{
+		ConfigureFailPoint: "failCommand",
+		Mode:               Mode{Times: times},
+		Data: Data{
+			FailCommands:    []string{cmdName},
+			CloseConnection: true,
+		},
+	}
+}
+
+// MaxTimeMSExpiredCode is the code the server fails operations with when
+// they exceed their maxTimeMS.
+const MaxTimeMSExpiredCode int32 = codes.MaxTimeMSExpired
+
+// NewMaxTimeExpired creates a FailPoint that will cause the specified command
+// to fail once with MaxTimeMSExpired, as if it ran out of server-side time.
+func NewMaxTimeExpired(cmdName string) FailPoint {
+	return NewSingleErr(cmdName, MaxTimeMSExpiredCode)
+}
+
+// NewMaxTimeAlwaysTimeOut creates a maxTimeAlwaysTimeOut FailPoint, which
+// makes every operation sent with a maxTimeMS fail with MaxTimeMSExpired
+// until disabled. Unlike NewMaxTimeExpired, the server takes its own timeout
+// path, so operations without a maxTimeMS are unaffected.
+func NewMaxTimeAlwaysTimeOut() FailPoint {
+	return FailPoint{
+		ConfigureFailPoint: MaxTimeAlwaysTimeOut,
+		Mode:               ModeAlwaysOn,
+	}
+}
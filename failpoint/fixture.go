@@ -0,0 +1,152 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads fail points from a fixture file. The file holds either a single
+// configureFailPoint document or a list of them, in the shape the driver spec
+// tests use:
+//
+//	configureFailPoint: failCommand
+//	mode: { times: 2 }
+//	data:
+//	  failCommands: [insert]
+//	  errorCode: 91
+//
+// Files ending in .json are read as extended JSON; anything else as YAML.
+// failCommand data is decoded into Data when every field is one Data knows,
+// so the With* builders apply; otherwise, and for other fail points, the data
+// is kept verbatim.
+func Load(path string) ([]FailPoint, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fail point fixture: %w", err)
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		if b, err = yamlToJSON(b); err != nil {
+			return nil, fmt.Errorf("parse fail point fixture %s: %w", path, err)
+		}
+	}
+
+	// Extended JSON must be a document at the top level, so wrap the
+	// contents to accept both a document and a list.
+	wrapped := append(append([]byte(`{"v":`), b...), '}')
+
+	type rawFailPoint struct {
+		ConfigureFailPoint string   `bson:"configureFailPoint"`
+		Mode               any      `bson:"mode"`
+		Data               bson.Raw `bson:"data,omitempty"`
+	}
+
+	var raws []rawFailPoint
+
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		var doc struct {
+			V []rawFailPoint `bson:"v"`
+		}
+
+		err = bson.UnmarshalExtJSON(wrapped, false, &doc)
+		raws = doc.V
+	} else {
+		var doc struct {
+			V rawFailPoint `bson:"v"`
+		}
+
+		err = bson.UnmarshalExtJSON(wrapped, false, &doc)
+		raws = []rawFailPoint{doc.V}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("decode fail point fixture %s: %w", path, err)
+	}
+
+	fps := make([]FailPoint, 0, len(raws))
+	for i, raw := range raws {
+		if raw.ConfigureFailPoint == "" {
+			return nil, fmt.Errorf("fail point %d in %s has no configureFailPoint", i, path)
+		}
+
+		fp := FailPoint{ConfigureFailPoint: raw.ConfigureFailPoint, Mode: raw.Mode}
+		if raw.Data != nil {
+			fp.Data = raw.Data
+		}
+
+		if raw.ConfigureFailPoint == FailCommand && raw.Data != nil && onlyDataFields(raw.Data) {
+			var data Data
+			if err := bson.Unmarshal(raw.Data, &data); err != nil {
+				return nil, fmt.Errorf("decode data of fail point %d in %s: %w", i, path, err)
+			}
+
+			fp.Data = data
+		}
+
+		fps = append(fps, fp)
+	}
+
+	return fps, nil
+}
+
+// EnableFromFile sets the fail points in the fixture at path through client
+// as a group. See Load and EnableGroup.
+func EnableFromFile[C Client](t *testing.T, client C, path string) TeardownFunc {
+	t.Helper()
+
+	fps, err := Load(path)
+	require.NoError(t, err, "failed to load fail points")
+
+	return EnableGroup(t, client, fps...)
+}
+
+// yamlToJSON converts a YAML document to JSON, which extended JSON accepts.
+func yamlToJSON(b []byte) ([]byte, error) {
+	var v any
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// onlyDataFields reports whether every field of raw is a field of Data, so
+// decoding into Data loses nothing.
+func onlyDataFields(raw bson.Raw) bool {
+	elems, err := raw.Elements()
+	if err != nil {
+		return false
+	}
+
+	known := map[string]bool{}
+
+	typ := reflect.TypeFor[Data]()
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("bson"), ",")
+		known[name] = true
+	}
+
+	for _, elem := range elems {
+		if !known[elem.Key()] {
+			return false
+		}
+	}
+
+	return true
+}
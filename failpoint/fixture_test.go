@@ -0,0 +1,114 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestLoad(t *testing.T) {
+	rawDoc := func(t *testing.T, d bson.D) bson.Raw {
+		t.Helper()
+
+		b, err := bson.Marshal(d)
+		require.NoError(t, err)
+
+		return b
+	}
+
+	for _, tc := range []struct {
+		name    string
+		file    string
+		want    func(t *testing.T) []FailPoint
+		wantErr string
+	}{
+		{
+			name: "single yaml",
+			file: "single.yaml",
+			want: func(*testing.T) []FailPoint {
+				return []FailPoint{{
+					ConfigureFailPoint: FailCommand,
+					Mode:               bson.D{{Key: "times", Value: int32(2)}},
+					Data:               Data{FailCommands: []string{"insert"}, ErrorCode: 91},
+				}}
+			},
+		},
+		{
+			name: "single json",
+			file: "single.json",
+			want: func(*testing.T) []FailPoint {
+				return []FailPoint{{
+					ConfigureFailPoint: FailCommand,
+					Mode:               ModeAlwaysOn,
+					Data:               Data{FailCommands: []string{"find"}, BlockConnection: true, BlockTimeMS: 500},
+				}}
+			},
+		},
+		{
+			name: "yaml list with another fail point",
+			file: "list.yml",
+			want: func(t *testing.T) []FailPoint {
+				return []FailPoint{
+					{
+						ConfigureFailPoint: FailCommand,
+						Mode:               bson.D{{Key: "skip", Value: int32(1)}},
+						Data:               Data{FailCommands: []string{"update"}, CloseConnection: true},
+					},
+					{
+						ConfigureFailPoint: "failGetMoreAfterCursorCheckout",
+						Mode:               ModeAlwaysOn,
+						Data:               rawDoc(t, bson.D{{Key: "errorCode", Value: int32(237)}}),
+					},
+				}
+			},
+		},
+		{
+			name: "json list with unknown data field",
+			file: "list.json",
+			want: func(t *testing.T) []FailPoint {
+				return []FailPoint{
+					{
+						ConfigureFailPoint: FailCommand,
+						Mode:               bson.D{{Key: "times", Value: int32(1)}},
+						Data: rawDoc(t, bson.D{
+							{Key: "failCommands", Value: bson.A{"insert"}},
+							{Key: "errorCode", Value: int32(112)},
+							{Key: "namespace", Value: "db.coll"},
+						}),
+					},
+					{ConfigureFailPoint: FailCommand, Mode: ModeOff},
+				}
+			},
+		},
+		{
+			name:    "no configureFailPoint",
+			file:    "nameless.yaml",
+			wantErr: "has no configureFailPoint",
+		},
+		{
+			name:    "missing file",
+			file:    "missing.yaml",
+			wantErr: "read fail point fixture",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fps, err := Load(filepath.Join("testdata", tc.file))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want(t), fps)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// EnableGroup sets every fail point in fps through client and returns a
+// single TeardownFunc that disables them all. Disabling is best-effort: every
+// fail point is attempted and the errors are reported together. If any fail
+// point can't be enabled, the ones already set are disabled before the test
+// fails. Like Enable, the group is disabled when the test ends.
+func EnableGroup[C Client](t *testing.T, client C, fps ...FailPoint) TeardownFunc {
+	t.Helper()
+
+	r := NewRunner(client)
+
+	disables := make([]DisableFunc, 0, len(fps))
+
+	var (
+		once       sync.Once
+		disableErr error
+	)
+
+	disableAll := func() error {
+		once.Do(func() {
+			// Undo in reverse, so fail points enabled twice end up off.
+			for _, disable := range slices.Backward(disables) {
+				disableErr = errors.Join(disableErr, disable(context.Background()))
+			}
+		})
+
+		return disableErr
+	}
+
+	for _, fp := range fps {
+		disable, _, err := enable(context.Background(), r, fp, enableOptions{})
+		if err != nil {
+			require.NoError(t, errors.Join(err, disableAll()), "error enabling failpoint group")
+		}
+
+		disables = append(disables, disable)
+	}
+
+	t.Cleanup(func() {
+		if err := disableAll(); err != nil && !isClientDisconnected(err) {
+			t.Errorf("error disabling failpoint group: %v", err)
+		}
+	})
+
+	return func(t *testing.T) {
+		require.NoError(t, disableAll())
+	}
+}
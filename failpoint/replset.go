@@ -0,0 +1,100 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Env is a deployment to target fail points at by member role. Both
+// *mongolocal.Env and *det.Env satisfy it.
+type Env interface {
+	ConnectionString() string
+}
+
+// EnableOnPrimary sets fp on the current primary of env's replica set over a
+// direct connection, so it only affects operations routed to the primary.
+// clientOpts (e.g. credentials or TLS) are applied to the discovery and
+// direct connections. fp is disabled when the test ends, or earlier by
+// calling the returned TeardownFunc.
+func EnableOnPrimary(t *testing.T, env Env, fp FailPoint, clientOpts ...*options.ClientOptions) TeardownFunc {
+	t.Helper()
+
+	primary, _ := replicaSetMembers(t, env, clientOpts)
+
+	return enableOnHosts(t, []string{primary}, fp, clientOpts)
+}
+
+// EnableOnSecondary is EnableOnPrimary for every secondary of env's replica
+// set, so a secondary read preference hits fp whichever member is selected.
+func EnableOnSecondary(t *testing.T, env Env, fp FailPoint, clientOpts ...*options.ClientOptions) TeardownFunc {
+	t.Helper()
+
+	_, secondaries := replicaSetMembers(t, env, clientOpts)
+	require.NotEmpty(t, secondaries, "replica set has no secondaries")
+
+	return enableOnHosts(t, secondaries, fp, clientOpts)
+}
+
+// replicaSetMembers returns the primary and the secondaries of env's replica
+// set, as named in the replica set config, according to hello.
+func replicaSetMembers(t *testing.T, env Env, clientOpts []*options.ClientOptions) (string, []string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	opts := append([]*options.ClientOptions{options.Client().ApplyURI(env.ConnectionString())}, clientOpts...)
+
+	client, err := mongo.Connect(opts...)
+	require.NoError(t, err, "failed to connect to discover replica set members")
+
+	defer func() {
+		require.NoError(t, client.Disconnect(ctx))
+	}()
+
+	primary, secondaries, err := helloMembers(ctx, client)
+	require.NoError(t, err, "failed to discover replica set members")
+
+	return primary, secondaries
+}
+
+// helloMembers reads the primary and secondaries from hello.
+func helloMembers(ctx context.Context, client *mongo.Client) (string, []string, error) {
+	var hello struct {
+		SetName string   `bson:"setName"`
+		Primary string   `bson:"primary"`
+		Hosts   []string `bson:"hosts"`
+	}
+
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return "", nil, fmt.Errorf("run hello: %w", err)
+	}
+
+	if hello.SetName == "" {
+		return "", nil, errors.New("deployment is not a replica set")
+	}
+
+	if hello.Primary == "" {
+		return "", nil, fmt.Errorf("replica set %s has no primary", hello.SetName)
+	}
+
+	// hosts lists the data-bearing, electable members, including the
+	// primary.
+	secondaries := slices.DeleteFunc(hello.Hosts, func(h string) bool { return h == hello.Primary })
+
+	return hello.Primary, secondaries, nil
+}
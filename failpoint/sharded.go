@@ -0,0 +1,119 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// mongosPingWindow is how recently a router must have pinged the config
+// servers for MongosHosts to consider it alive.
+const mongosPingWindow = time.Minute
+
+// EnableOnAllMongos sets fp on every mongos in hosts ("host:port") over direct
+// connections. Enabling through a pooled client only configures whichever
+// router the command is routed to, which makes sharded fail point tests
+// flaky. clientOpts (e.g. credentials or TLS) are applied to each direct
+// connection. fp is disabled on every router when the test ends, or
+// earlier by calling the returned TeardownFunc.
+func EnableOnAllMongos(t *testing.T, hosts []string, fp FailPoint, clientOpts ...*options.ClientOptions) TeardownFunc {
+	t.Helper()
+
+	return enableOnHosts(t, hosts, fp, clientOpts)
+}
+
+// enableOnHosts sets fp on each of hosts over a direct connection, disabling
+// it when the test ends or the returned TeardownFunc is called.
+func enableOnHosts(t *testing.T, hosts []string, fp FailPoint, clientOpts []*options.ClientOptions) TeardownFunc {
+	t.Helper()
+
+	teardowns := make([]TeardownFunc, 0, len(hosts))
+	clients := make([]*mongo.Client, 0, len(hosts))
+
+	var once sync.Once
+
+	teardown := func(t *testing.T) {
+		t.Helper()
+
+		once.Do(func() {
+			for _, td := range teardowns {
+				td(t)
+			}
+
+			for _, c := range clients {
+				require.NoError(t, c.Disconnect(context.Background()))
+			}
+		})
+	}
+
+	for _, host := range hosts {
+		opts := append(slices.Clone(clientOpts), options.Client().SetHosts([]string{host}).SetDirect(true))
+
+		client, err := mongo.Connect(opts...)
+		if err != nil {
+			teardown(t)
+			t.Fatalf("failed to connect to %s: %v", host, err)
+		}
+
+		clients = append(clients, client)
+		teardowns = append(teardowns, Enable(t, client, fp))
+	}
+
+	// Registered after the per-host cleanups, so it runs first and disables
+	// fp before the direct connections go away.
+	t.Cleanup(func() { teardown(t) })
+
+	return teardown
+}
+
+// EnableOnDiscoveredMongos is EnableOnAllMongos for every router that client's
+// cluster reports as alive. See MongosHosts.
+func EnableOnDiscoveredMongos(t *testing.T, client *mongo.Client, fp FailPoint, clientOpts ...*options.ClientOptions) TeardownFunc {
+	t.Helper()
+
+	hosts, err := MongosHosts(context.Background(), client)
+	require.NoError(t, err, "failed to discover mongos routers")
+	require.NotEmpty(t, hosts, "no live mongos routers found")
+
+	return EnableOnAllMongos(t, hosts, fp, clientOpts...)
+}
+
+// MongosHosts returns the routers of client's sharded cluster that pinged
+// the config servers recently, as recorded in config.mongos. The addresses
+// are the ones the routers report for themselves.
+func MongosHosts(ctx context.Context, client *mongo.Client) ([]string, error) {
+	filter := bson.D{{Key: "ping", Value: bson.D{{Key: "$gte", Value: time.Now().Add(-mongosPingWindow)}}}}
+
+	cursor, err := client.Database("config").Collection("mongos").Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list config.mongos: %w", err)
+	}
+
+	var routers []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &routers); err != nil {
+		return nil, fmt.Errorf("decode config.mongos: %w", err)
+	}
+
+	hosts := make([]string, 0, len(routers))
+	for _, r := range routers {
+		hosts = append(hosts, r.ID)
+	}
+
+	return hosts, nil
+}
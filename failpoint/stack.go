@@ -0,0 +1,108 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"reflect"
+	"sync"
+)
+
+// EnableOption configures Enable.
+type EnableOption func(*enableOptions)
+
+type enableOptions struct {
+	restorePrevious bool
+}
+
+// WithRestorePrevious makes the teardown re-apply the configuration fp
+// replaced, rather than turning the fail point off, if the same fail point
+// was already enabled through the same client. This makes nested use safe:
+//
+//	failpoint.Enable(t, client, outer)
+//	inner := failpoint.Enable(t, client, fp, failpoint.WithRestorePrevious())
+//	...
+//	inner(t) // outer is in effect again
+//
+// The replaced configuration is re-applied as it was first given, so a
+// {times: n} or {skip: n} mode starts counting from n again; activations
+// that happened before it was replaced are not carried over.
+func WithRestorePrevious() EnableOption {
+	return func(o *enableOptions) {
+		o.restorePrevious = true
+	}
+}
+
+// activeKey identifies a fail point enabled through a runner.
+type activeKey struct {
+	r    Runner
+	name string
+}
+
+// activeEntry is one configuration in an activeKey's stack.
+type activeEntry struct {
+	fp FailPoint
+}
+
+var (
+	activeMu sync.Mutex
+	active   = map[activeKey][]*activeEntry{}
+)
+
+// track records fp as enabled through r, returning its entry and the
+// configuration it replaced, if any. Runners that can't be used as map keys
+// aren't tracked, so their stacking goes undetected.
+func track(r Runner, fp FailPoint) (*activeEntry, *FailPoint) {
+	if !reflect.ValueOf(r).Comparable() {
+		return nil, nil
+	}
+
+	activeMu.Lock()
+	defer activeMu.Unlock()
+
+	key := activeKey{r: r, name: fp.ConfigureFailPoint}
+	stack := active[key]
+
+	var prev *FailPoint
+	if len(stack) > 0 {
+		prev = &stack[len(stack)-1].fp
+	}
+
+	entry := &activeEntry{fp: fp}
+	active[key] = append(stack, entry)
+
+	return entry, prev
+}
+
+// untrack removes entry and returns the configuration now on top of its
+// stack, if any.
+func untrack(r Runner, entry *activeEntry) *FailPoint {
+	if entry == nil {
+		return nil
+	}
+
+	activeMu.Lock()
+	defer activeMu.Unlock()
+
+	key := activeKey{r: r, name: entry.fp.ConfigureFailPoint}
+
+	stack := active[key]
+	for i, e := range stack {
+		if e == entry {
+			stack = append(stack[:i:i], stack[i+1:]...)
+			break
+		}
+	}
+
+	if len(stack) == 0 {
+		delete(active, key)
+		return nil
+	}
+
+	active[key] = stack
+
+	return &stack[len(stack)-1].fp
+}
@@ -0,0 +1,95 @@
+// Copyright (C) MongoDB, Inc. 2024-present.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License. You may obtain
+// a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+package failpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore"
+)
+
+// IsEnabled reports whether the fail point name is enabled on the server
+// client's command is routed to, along with the fail point's status document
+// ({mode, timesEntered, data}). Use it to check a fail point took effect
+// before running the operation under test.
+//
+// The status comes from the serverStatus failPoints section, which the server
+// only exposes when test commands are enabled.
+func IsEnabled[C Client](ctx context.Context, client C, name string) (bool, bson.Raw, error) {
+	status, err := failPointStatus(ctx, NewRunner(client), name)
+	if err != nil {
+		return false, nil, err
+	}
+
+	mode, err := status.LookupErr("mode")
+	if err != nil {
+		return false, nil, fmt.Errorf("fail point %q status has no mode: %w", name, err)
+	}
+
+	// The server reports the mode as a number; 0 is off.
+	modeNum, ok := mode.AsInt64OK()
+	if !ok {
+		return false, nil, fmt.Errorf("fail point %q has unexpected mode %v", name, mode)
+	}
+
+	return modeNum != 0, status, nil
+}
+
+// Count returns how many times the fail point name has fired since it was
+// last configured: the timesEntered counter the server also reports as
+// "count" in configureFailPoint responses. With a fail point on a retried
+// command, a count of 3 means the operation was attempted three times.
+func Count[C Client](ctx context.Context, client C, name string) (int64, error) {
+	status, err := failPointStatus(ctx, NewRunner(client), name)
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := status.LookupErr("timesEntered")
+	if err != nil {
+		return 0, fmt.Errorf("fail point %q status has no timesEntered: %w", name, err)
+	}
+
+	n, ok := val.AsInt64OK()
+	if !ok {
+		return 0, fmt.Errorf("fail point %q has unexpected timesEntered %v", name, val)
+	}
+
+	return n, nil
+}
+
+// failPointStatus returns the serverStatus entry for the fail point name.
+func failPointStatus(ctx context.Context, r Runner, name string) (bson.Raw, error) {
+	cmd := bson.D{
+		{Key: "serverStatus", Value: 1},
+		{Key: "failPoints", Value: 1},
+	}
+
+	res, err := r.RunAdminCommand(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("run serverStatus: %w", err)
+	}
+
+	val, err := res.LookupErr("failPoints", name)
+	if errors.Is(err, bsoncore.ErrElementNotFound) {
+		return nil, fmt.Errorf("server has no fail point %q", name)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("look up fail point %q: %w", name, err)
+	}
+
+	status, ok := val.DocumentOK()
+	if !ok {
+		return nil, fmt.Errorf("fail point %q status is a %v, not a document", name, val.Type)
+	}
+
+	return status, nil
+}
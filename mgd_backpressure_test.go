@@ -92,10 +92,7 @@ func TestMGD_Backpressure_MaxAdaptiveRetriesGoverns(t *testing.T) {
 
 	require.Error(t, err, "find should fail when retry budget is exhausted before failpoint")
 
-	var srvErr mongo.ServerError
-	require.True(t, errors.As(err, &srvErr), "expected ServerError, got %T: %v", err, err)
-	require.True(t, srvErr.HasErrorCode(int(failpoint.OverloadErrorCode)),
-		"expected error %d, got %v", failpoint.OverloadErrorCode, srvErr.ErrorCodes())
+	failpoint.RequireErrorCode(t, err, int(failpoint.OverloadErrorCode))
 
 	starts := mon.CommandStartedEvents()
 	require.Len(t, starts, 2, "expected 1 original find + 1 retry, got %d", len(starts))
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/prestonvasquez/go-playground/failpoint"
+	"github.com/prestonvasquez/go-playground/failpoint/codes"
 	"github.com/prestonvasquez/go-playground/mongolocal"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -77,7 +78,7 @@ func TestMGD_ClientBulkWrite_PartialCursorOnGetMoreError(t *testing.T) {
 	fpTeardown := failpoint.Enable(t, client, failpoint.FailPoint{
 		ConfigureFailPoint: "failCommand",
 		Mode:               failpoint.Mode{Skip: 1, Times: 1},
-		Data:               failpoint.Data{FailCommands: []string{"getMore"}, ErrorCode: 8},
+		Data:               failpoint.Data{FailCommands: []string{"getMore"}, ErrorCode: codes.UnknownError},
 	})
 	defer fpTeardown(t)
 
@@ -2,17 +2,16 @@ package goplayground
 
 import (
 	"context"
-	"errors"
 	"testing"
 	"time"
 
 	"github.com/prestonvasquez/go-playground/det"
 	"github.com/prestonvasquez/go-playground/failpoint"
+	"github.com/prestonvasquez/go-playground/failpoint/codes"
 	"github.com/prestonvasquez/go-playground/mongolocal"
+	"github.com/prestonvasquez/go-playground/monitor"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/event"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/topology"
@@ -64,7 +63,7 @@ func TestMGD_CSOT_V1_ContextDeadlineWithoutSetTimeout(t *testing.T) {
 	defer teardown(t)
 
 	// Block a find command for 20 seconds.
-	fpTeardown := failpoint.EnableV1(t, client, failpoint.NewSingleBlock("find", 20000))
+	fpTeardown := failpoint.Enable(t, client, failpoint.NewSingleBlock("find", 20000))
 	defer fpTeardown(t)
 
 	bgReadCalled := false
@@ -99,7 +98,7 @@ func TestMGD_CSOT_V1_ContextDeadlineWithSetTimeout(t *testing.T) {
 	defer teardown(t)
 
 	// Block a find command for 20 seconds.
-	fpTeardown := failpoint.EnableV1(t, client, failpoint.NewSingleBlock("find", 20000))
+	fpTeardown := failpoint.Enable(t, client, failpoint.NewSingleBlock("find", 20000))
 	defer fpTeardown(t)
 
 	bgReadCalled := false
@@ -158,7 +157,7 @@ func TestMGD_CSOT_WithTransaction_InheritTimeoutMS_ClientLevel(t *testing.T) {
 
 	// Expect a timeout error from WithTransaction.
 	require.Error(t, err, "expected error from WithTransaction")
-	require.True(t, mongo.IsTimeout(err), "expected timeout error, got: %v", err)
+	failpoint.RequireTimeout(t, err)
 }
 
 func TestMGD_CSOT_WithTransaction_InheritTimeoutMS_OperationLevel(t *testing.T) {
@@ -191,7 +190,7 @@ func TestMGD_CSOT_WithTransaction_InheritTimeoutMS_OperationLevel(t *testing.T)
 
 	// Expect a timeout error from WithTransaction.
 	require.Error(t, err, "expected error from WithTransaction")
-	require.True(t, mongo.IsTimeout(err), "expected timeout error, got: %v", err)
+	failpoint.RequireTimeout(t, err)
 }
 
 // Test 7a: All write attempts made returns most recent error
@@ -202,40 +201,27 @@ func TestMGD_CSOT_WithTransaction_InheritTimeoutMS_OperationLevel(t *testing.T)
 // Per the spec: "If the driver has encountered only errors that indicate write
 // attempts were made, the most recently encountered error must be returned."
 func TestMGD_CSOT_RetryableWrite_7a_AllWriteAttemptsMade(t *testing.T) {
-	setupCh := make(chan func(), 1)
-
 	// Step 1: Create a client with retryWrites=true.
-	monitor := &event.CommandMonitor{
-		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
-			if evt.CommandName == "insert" {
-				select {
-				case setup := <-setupCh:
-					setup()
-				default:
-				}
-			}
-		},
-	}
+	rec := monitor.NewRecorder(monitor.WithCommands("insert"))
 
 	client, teardown := mongolocal.StartT(t, context.Background(),
 		mongolocal.WithReplicaSet("rs0"),
 		mongolocal.WithEnableTestCommands(),
-		mongolocal.WithMongoClientOptions(options.Client().SetMonitor(monitor).SetRetryWrites(true)))
+		mongolocal.WithRecorder(rec),
+		mongolocal.WithMongoClientOptions(options.Client().SetRetryWrites(true)))
 	defer teardown(t)
 
 	// Step 2: Configure a fail point with error code 134
 	// (ReadConcernMajorityNotAvailableYet).
 	failpoint.Enable(t, client,
-		failpoint.NewSingleErrWithLabels("insert", 134, []string{"RetryableWriteError"}))
+		failpoint.NewSingleErrWithLabels("insert", codes.ReadConcernMajorityNotAvailableYet, []string{"RetryableWriteError"}))
 
 	// Step 3: Via the CommandFailedEvent, configure a fail point with error code
 	// 10107 (NotWritablePrimary). Drivers SHOULD only configure the `10107` fail
 	// point command if the the failed event is for the `134` error configured in
 	// step 2.
-	setupCh <- func() {
-		failpoint.Enable(t, client,
-			failpoint.NewAlwaysOnErrWithLabels("insert", 10107, []string{"RetryableWriteError"}))
-	}
+	rec.Chain(failpoint.EnableAfterCommandFailure(t, client, "insert", codes.ReadConcernMajorityNotAvailableYet,
+		failpoint.NewAlwaysOnErrWithLabels("insert", codes.NotWritablePrimary, []string{"RetryableWriteError"})))
 
 	// Step 4: Set a 5s timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -247,9 +233,7 @@ func TestMGD_CSOT_RetryableWrite_7a_AllWriteAttemptsMade(t *testing.T) {
 
 	require.Error(t, err)
 
-	var srvErr mongo.ServerError
-	require.True(t, errors.As(err, &srvErr))
-	require.True(t, srvErr.HasErrorCode(10107), "expected error 10107, got %v", srvErr.ErrorCodes())
+	failpoint.RequireErrorCode(t, err, codes.NotWritablePrimary)
 
 	// Step 6: Disable the fail point (handled by test cleanup).
 }
@@ -263,41 +247,27 @@ func TestMGD_CSOT_RetryableWrite_7a_AllWriteAttemptsMade(t *testing.T) {
 // contain the NoWritesPerformed error label or are client-side errors before
 // a command is sent), the first error encountered must be returned."
 func TestMGD_CSOT_RetryableWrite_7b_NoWriteAttemptsMade(t *testing.T) {
-	setupCh := make(chan func(), 1)
-
 	// Step 1: Create a client with retryWrites=true.
-	monitor := &event.CommandMonitor{
-		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
-			errorCodes := mongo.ErrorCodes(evt.Failure)
-			if evt.CommandName == "insert" && len(errorCodes) > 0 && errorCodes[0] == 134 {
-				select {
-				case setup := <-setupCh:
-					setup()
-				default:
-				}
-			}
-		},
-	}
+	rec := monitor.NewRecorder(monitor.WithCommands("insert"))
 
 	client, teardown := mongolocal.StartT(t, context.Background(),
 		mongolocal.WithReplicaSet("rs0"),
 		mongolocal.WithEnableTestCommands(),
-		mongolocal.WithMongoClientOptions(options.Client().SetMonitor(monitor).SetRetryWrites(true)))
+		mongolocal.WithRecorder(rec),
+		mongolocal.WithMongoClientOptions(options.Client().SetRetryWrites(true)))
 	defer teardown(t)
 
 	// Step 2: Configure a fail point with error code 134
 	// (ReadConcernMajorityNotAvailableYet) and NoWritesPerformed.
 	failpoint.Enable(t, client,
-		failpoint.NewSingleErrWithLabels("insert", 134, []string{"RetryableWriteError", "NoWritesPerformed"}))
+		failpoint.NewSingleErrWithLabels("insert", codes.ReadConcernMajorityNotAvailableYet, []string{"RetryableWriteError", "NoWritesPerformed"}))
 
 	// Step 3: Via the CommandFailedEvent, configure a fail point with error code
 	// 10107 (NoWritablePrimary) and NoWritesPerformed. Drivers SHOULD only
 	// configure the `10107` fail point command if the the failed event is for the
 	// `134` error configured in step 2.
-	setupCh <- func() {
-		failpoint.Enable(t, client,
-			failpoint.NewAlwaysOnErrWithLabels("insert", 10107, []string{"RetryableWriteError", "NoWritesPerformed"}))
-	}
+	rec.Chain(failpoint.EnableAfterCommandFailure(t, client, "insert", codes.ReadConcernMajorityNotAvailableYet,
+		failpoint.NewAlwaysOnErrWithLabels("insert", codes.NotWritablePrimary, []string{"RetryableWriteError", "NoWritesPerformed"})))
 
 	// Step 4: Set a 1s timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -309,9 +279,7 @@ func TestMGD_CSOT_RetryableWrite_7b_NoWriteAttemptsMade(t *testing.T) {
 
 	require.Error(t, err)
 
-	var srvErr mongo.ServerError
-	require.True(t, errors.As(err, &srvErr))
-	require.True(t, srvErr.HasErrorCode(134), "expected error 134, got %v", srvErr.ErrorCodes())
+	failpoint.RequireErrorCode(t, err, codes.ReadConcernMajorityNotAvailableYet)
 
 	// Step 6: Disable the fail point (handled by test cleanup).
 }
@@ -327,41 +295,28 @@ func TestMGD_CSOT_RetryableWrite_7b_NoWriteAttemptsMade(t *testing.T) {
 // the most recently encountered error which indicates a write attempt occurred
 // must be returned."
 func TestMGD_CSOT_RetryableWrite_7c_MixedWriteAttempts(t *testing.T) {
-	setupCh := make(chan func(), 1)
-
 	// Step 1: Create a client with retryWrites=true.
-	monitor := &event.CommandMonitor{
-		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
-			if evt.CommandName == "insert" {
-				select {
-				case setup := <-setupCh:
-					setup()
-				default:
-				}
-			}
-		},
-	}
+	rec := monitor.NewRecorder(monitor.WithCommands("insert"))
 
 	client, teardown := mongolocal.StartT(t, context.Background(),
 		mongolocal.WithReplicaSet("rs0"),
 		mongolocal.WithEnableTestCommands(),
-		mongolocal.WithMongoClientOptions(options.Client().SetMonitor(monitor).SetRetryWrites(true)))
+		mongolocal.WithRecorder(rec),
+		mongolocal.WithMongoClientOptions(options.Client().SetRetryWrites(true)))
 	defer teardown(t)
 
 	// Step 2: Configure a fail point with error code 134
 	// (ReadConcernMajorityNotAvailableYet) WITHOUT NoWritesPerformed (write attempt
 	// was made).
 	failpoint.Enable(t, client,
-		failpoint.NewSingleErrWithLabels("insert", 134, []string{"RetryableWriteError"}))
+		failpoint.NewSingleErrWithLabels("insert", codes.ReadConcernMajorityNotAvailableYet, []string{"RetryableWriteError"}))
 
 	// Step 3: Via the CommandFailedEvent, configure a fail point with error code
 	// 10107 (NotWritablePrimary) WITH NoWritesPerformed. Drivers SHOULD only
 	// configure the `10107` fail point command if the the failed event is for the
 	// `134` error configured in step 2.
-	setupCh <- func() {
-		failpoint.Enable(t, client,
-			failpoint.NewAlwaysOnErrWithLabels("insert", 10107, []string{"RetryableWriteError", "NoWritesPerformed"}))
-	}
+	rec.Chain(failpoint.EnableAfterCommandFailure(t, client, "insert", codes.ReadConcernMajorityNotAvailableYet,
+		failpoint.NewAlwaysOnErrWithLabels("insert", codes.NotWritablePrimary, []string{"RetryableWriteError", "NoWritesPerformed"})))
 
 	// Step 4: Set a 5s timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -373,9 +328,7 @@ func TestMGD_CSOT_RetryableWrite_7c_MixedWriteAttempts(t *testing.T) {
 
 	require.Error(t, err)
 
-	var srvErr mongo.ServerError
-	require.True(t, errors.As(err, &srvErr))
-	require.True(t, srvErr.HasErrorCode(134), "expected error 134, got %v", srvErr.ErrorCodes())
+	failpoint.RequireErrorCode(t, err, codes.ReadConcernMajorityNotAvailableYet)
 
 	// Step 6: Disable the fail point (handled by test cleanup).
 }
@@ -2,10 +2,10 @@ package goplayground
 
 import (
 	"context"
-	"errors"
 	"testing"
 
 	"github.com/prestonvasquez/go-playground/failpoint"
+	"github.com/prestonvasquez/go-playground/failpoint/codes"
 	"github.com/prestonvasquez/go-playground/mongolocal"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -21,7 +21,7 @@ func TestMGD_Failpoint_Enable(t *testing.T) {
 	defer teardown(t)
 
 	// Set failpoint and run operation using same client.
-	failpointTeardown := failpoint.Enable(t, client, failpoint.NewAlwaysOnErr("find", 91))
+	failpointTeardown := failpoint.Enable(t, client, failpoint.NewAlwaysOnErr("find", codes.ShutdownInProgress))
 	defer failpointTeardown(t)
 
 	err := mongolocal.ArbColl(client).FindOne(context.Background(), bson.D{}).Err()
@@ -29,9 +29,7 @@ func TestMGD_Failpoint_Enable(t *testing.T) {
 	require.Error(t, err, "expected error from failpoint")
 	t.Logf("Got error: %v (type: %T)", err, err)
 
-	var srvErr mongo.ServerError
-	require.True(t, errors.As(err, &srvErr), "expected ServerError, got %T: %v", err, err)
-	require.True(t, srvErr.HasErrorCode(91), "expected error 91, got %v", srvErr.ErrorCodes())
+	failpoint.RequireErrorCode(t, err, codes.ShutdownInProgress)
 }
 
 func TestMGD_Failpoint_SetFromDifferentClients(t *testing.T) {
@@ -47,7 +45,7 @@ func TestMGD_Failpoint_SetFromDifferentClients(t *testing.T) {
 	require.NoError(t, err, "error connecting client2")
 
 	// Set failpoint and run operation using same client.
-	failpointTeardown := failpoint.Enable(t, client2, failpoint.NewAlwaysOnErr("find", 91))
+	failpointTeardown := failpoint.Enable(t, client2, failpoint.NewAlwaysOnErr("find", codes.ShutdownInProgress))
 	defer failpointTeardown(t)
 
 	err = mongolocal.ArbColl(client1).FindOne(context.Background(), bson.D{}).Err()
@@ -55,9 +53,7 @@ func TestMGD_Failpoint_SetFromDifferentClients(t *testing.T) {
 	require.Error(t, err, "expected error from failpoint")
 	t.Logf("Got error: %v (type: %T)", err, err)
 
-	var srvErr mongo.ServerError
-	require.True(t, errors.As(err, &srvErr), "expected ServerError, got %T: %v", err, err)
-	require.True(t, srvErr.HasErrorCode(91), "expected error 91, got %v", srvErr.ErrorCodes())
+	failpoint.RequireErrorCode(t, err, codes.ShutdownInProgress)
 }
 
 //func TestFailpoint_SetFromDifferentClient(t *testing.T) {
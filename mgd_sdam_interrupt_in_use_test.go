@@ -97,16 +97,7 @@ func TestMGD_SDAM_MonitorTimeout_InterruptsInUseCursor(t *testing.T) {
 	// the monitor observes two consecutive timeouts -> clearAll (interrupt
 	// in-use). getMore is NOT in failCommands, so the cursor is killed by the
 	// pool clear, not by the failpoint directly.
-	fp := failpoint.FailPoint{
-		ConfigureFailPoint: "failCommand",
-		Mode:               failpoint.Mode{Times: 2},
-		Data: failpoint.Data{
-			FailCommands:    []string{"hello", "isMaster"},
-			AppName:         appName,
-			BlockConnection: true,
-			BlockTimeMS:     5000, // > connectTimeout (2s)
-		},
-	}
+	fp := failpoint.NewHeartbeatBlock(appName, 5000, 2) // > connectTimeout (2s)
 	fpTeardown := failpoint.Enable(t, client, fp)
 	defer fpTeardown(t)
 
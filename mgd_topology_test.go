@@ -2,7 +2,6 @@ package goplayground
 
 import (
 	"context"
-	"fmt"
 	"testing"
 	"time"
 
@@ -34,44 +33,9 @@ func TestMGDLatestTopologyDescription(t *testing.T) {
 
 	require.NoError(t, client.Ping(context.Background(), nil))
 
-	// awaitMinimumPoolSize waits for the client's connection pool to reach the
-	// specified minimum size. This is a best effort operation that times out after
-	// some predefined amount of time to avoid blocking tests indefinitely.
-	awaitMinimumPoolSize := func(
-		ctx context.Context,
-		sm *mongoevent.ServerMonitor,
-		pm *mongoevent.PoolMonitor,
-		minPoolSize uint64,
-	) error {
-		ticker := time.NewTicker(100 * time.Millisecond)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return fmt.Errorf("timed out waiting for client to reach minPoolSize")
-			case <-ticker.C:
-				ready := true
-				for _, server := range sm.LatestTopologyDescription().Servers {
-					if pm.ConnsReady(server.Addr.String()) < int(minPoolSize) {
-						ready = false
-
-						// If any server has less than minPoolSize connections, continue
-						// waiting.
-						break
-					}
-				}
-
-				if ready {
-					return nil
-				}
-			}
-		}
-	}
-
 	// Don't spend longer than 5s awaiting minPoolSize.
 	awaitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	require.NoError(t, awaitMinimumPoolSize(awaitCtx, serverM, poolM, minPoolSize))
+	require.NoError(t, mongoevent.AwaitMinPoolSize(awaitCtx, serverM, poolM, minPoolSize))
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/prestonvasquez/go-playground/failpoint/codes"
 	"github.com/prestonvasquez/go-playground/mongolocal"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -107,7 +108,7 @@ func TestMGD_V1_DirectSecondary_NotWritablePrimary(t *testing.T) {
 	// Assert we get a NotWritablePrimary error (error code 10107) .
 	var srcErr mongov1.ServerError
 	require.True(t, errors.As(err, &srcErr))
-	require.True(t, srcErr.HasErrorCode(10107))
+	require.True(t, srcErr.HasErrorCode(codes.NotWritablePrimary))
 }
 
 func TestMGD_V2_DirectSecondary_WriteBehavior(t *testing.T) {
@@ -140,5 +141,5 @@ func TestMGD_V2_DirectSecondary_WriteBehavior(t *testing.T) {
 	// Assert we get a NotWritablePrimary error (error code 10107).
 	var srvErr mongo.ServerError
 	require.True(t, errors.As(err, &srvErr))
-	require.True(t, srvErr.HasErrorCode(10107))
+	require.True(t, srvErr.HasErrorCode(codes.NotWritablePrimary))
 }
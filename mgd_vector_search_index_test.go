@@ -3,7 +3,6 @@ package goplayground
 import (
 	"context"
 	"testing"
-	"time"
 
 	"github.com/prestonvasquez/go-playground/atlaslocal"
 	"github.com/stretchr/testify/require"
@@ -69,7 +68,7 @@ func TestMGD_CreateVectorSearchIndex(t *testing.T) {
 		require.Equal(t, indexName, createdName)
 
 		// Verify index was created with correct definition
-		doc := waitForIndex(t, ctx, view, opts, indexName)
+		doc := awaitIndex(t, ctx, view, indexName)
 		verifyAutoEmbedIndex(t, doc, "voyage-4-large", "description", "text")
 	})
 
@@ -106,7 +105,7 @@ func TestMGD_CreateVectorSearchIndex(t *testing.T) {
 		}
 
 		// If compression becomes supported, verify it works
-		doc := waitForIndex(t, ctx, view, opts, indexName)
+		doc := awaitIndex(t, ctx, view, indexName)
 		verifyAutoEmbedIndex(t, doc, "voyage-4", "content", "text")
 
 		// Verify compression field exists
@@ -153,7 +152,7 @@ func TestMGD_CreateVectorSearchIndex(t *testing.T) {
 		}
 
 		// If hnswOptions becomes supported, verify it works
-		doc := waitForIndex(t, ctx, view, opts, indexName)
+		doc := awaitIndex(t, ctx, view, indexName)
 		verifyAutoEmbedIndex(t, doc, "voyage-4-lite", "title", "text")
 
 		// Verify hnswOptions field exists
@@ -237,32 +236,15 @@ func TestMGD_CreateVectorSearchIndex(t *testing.T) {
 }
 
 // Helper function to wait for an index to appear in the list
-func waitForIndex(t *testing.T, ctx context.Context, view mongo.SearchIndexView, opts *options.SearchIndexesOptionsBuilder, indexName string) bson.Raw {
+func awaitIndex(t *testing.T, ctx context.Context, view mongo.SearchIndexView, indexName string) bson.Raw {
 	t.Helper()
 
-	awaitCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+	status, err := atlaslocal.AwaitSearchIndex(ctx, view, indexName)
+	require.NoError(t, err, "failed to await search index")
 
-	var doc bson.Raw
-	for doc == nil {
-		cursor, err := view.List(awaitCtx, opts)
-		require.NoError(t, err, "failed to list")
+	t.Logf("Index found: %s", status.Raw.String())
 
-		if !cursor.Next(awaitCtx) {
-			time.Sleep(2 * time.Second)
-			continue
-		}
-
-		name := cursor.Current.Lookup("name").StringValue()
-		if name == indexName {
-			doc = cursor.Current
-			t.Logf("Index found: %s", cursor.Current.String())
-			break
-		}
-		time.Sleep(2 * time.Second)
-	}
-	require.NotNil(t, doc, "got empty document")
-	return doc
+	return status.Raw
 }
 
 // Helper function to verify autoEmbed index fields
@@ -0,0 +1,53 @@
+package mongoevent
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// awaitPollInterval is the mean interval between AwaitMinPoolSize checks.
+const awaitPollInterval = 100 * time.Millisecond
+
+// AwaitMinPoolSize waits until every server in the latest topology described
+// to sm has at least n ready connections in pm, e.g. for a client's
+// minPoolSize to be populated. It polls at a jittered interval and, if ctx is
+// done first, returns an error naming the servers that were short.
+func AwaitMinPoolSize(ctx context.Context, sm *ServerMonitor, pm *PoolMonitor, n uint64) error {
+	for {
+		short := shortServers(sm, pm, n)
+		if short == nil {
+			return nil
+		}
+
+		// Jitter by ±50% so concurrent waiters don't poll in lockstep.
+		interval := awaitPollInterval/2 + rand.N(awaitPollInterval)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("await minPoolSize %d: %s: %w", n, strings.Join(short, ", "), ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// shortServers describes the servers with fewer than n ready connections, or
+// returns nil if there are none.
+func shortServers(sm *ServerMonitor, pm *PoolMonitor, n uint64) []string {
+	servers := sm.LatestTopologyDescription().Servers
+	if len(servers) == 0 {
+		return []string{"no servers discovered"}
+	}
+
+	var short []string
+	for _, server := range servers {
+		addr := server.Addr.String()
+		if ready := pm.ConnsReady(addr); ready < int(n) {
+			short = append(short, fmt.Sprintf("%s has %d ready connections", addr, ready))
+		}
+	}
+
+	return short
+}
@@ -7,17 +7,24 @@ import (
 )
 
 // CommandMonitor monitors MongoDB commands and records failed errors.
+//
+// Deprecated: Use monitor.Recorder; its CommandFailedEvents carry the
+// failures.
 type CommandMonitor struct {
 	FailedErrors []error
 }
 
 // NewCommandMonitor creates a new CommandMonitor instance.
+//
+// Deprecated: Use monitor.NewRecorder.
 func NewCommandMonitor() *CommandMonitor {
 	return &CommandMonitor{}
 }
 
 // NewCommandEventMonitor creates a MongoDB event.CommandMonitor that records
 // failed command errors.
+//
+// Deprecated: Use monitor.Recorder.CommandMonitor.
 func NewCommandEventMonitor(monitor *CommandMonitor) *event.CommandMonitor {
 	return &event.CommandMonitor{
 		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
@@ -1,21 +1,76 @@
 package mongoevent
 
 import (
+	"maps"
+	"slices"
 	"sync"
+	"time"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/event"
 )
 
+// PoolCounts counts the connection pool events of one server.
+type PoolCounts struct {
+	Created        int
+	Ready          int
+	Closed         int
+	CheckedOut     int
+	CheckedIn      int
+	CheckOutFailed int
+	Cleared        int
+
+	// ClearedInterruptingInUse counts the clears that also closed in-use
+	// connections (interruptInUseConnections).
+	ClearedInterruptingInUse int
+
+	// ClosedByReason and CheckOutFailedByReason break Closed and
+	// CheckOutFailed down by event reason (event.ReasonStale, ...).
+	ClosedByReason         map[string]int
+	CheckOutFailedByReason map[string]int
+}
+
+// PoolClear is a captured pool cleared event.
+type PoolClear struct {
+	Time    time.Time
+	Address string
+
+	// ServiceID is the load balanced service whose connections were
+	// cleared, or nil outside load balanced deployments.
+	ServiceID *bson.ObjectID
+
+	// Generation is the pool generation the clear started for the address
+	// and service ID: 1 after the first clear, 2 after the second, and so on.
+	Generation uint64
+
+	// InterruptInUse reports whether the clear also closed in-use
+	// connections (interruptInUseConnections), as a CSOT or heartbeat
+	// timeout does.
+	InterruptInUse bool
+}
+
+// poolKey identifies a pool generation counter: one per address, or per
+// service behind a load balanced address.
+type poolKey struct {
+	addr      string
+	serviceID bson.ObjectID
+}
+
 // PoolMonitor is a monitor that captures connection pool events.
 type PoolMonitor struct {
 	mu             sync.RWMutex
 	connsPerServer map[string]int
+	counts         map[string]*PoolCounts
+	clears         []PoolClear
+	generations    map[poolKey]uint64
 }
 
 // NewPoolMonitor creates a new PoolMonitor.
 func NewPoolMonitor() *PoolMonitor {
 	return &PoolMonitor{
 		connsPerServer: make(map[string]int),
+		counts:         make(map[string]*PoolCounts),
+		generations:    make(map[poolKey]uint64),
 	}
 }
 
@@ -27,11 +82,48 @@ func NewPoolEventMonitor(monitor *PoolMonitor) *event.PoolMonitor {
 			monitor.mu.Lock()
 			defer monitor.mu.Unlock()
 
+			counts := monitor.counts[evt.Address]
+			if counts == nil {
+				counts = &PoolCounts{
+					ClosedByReason:         map[string]int{},
+					CheckOutFailedByReason: map[string]int{},
+				}
+				monitor.counts[evt.Address] = counts
+			}
+
 			switch evt.Type {
+			case event.ConnectionCreated:
+				counts.Created++
 			case event.ConnectionReady:
 				monitor.connsPerServer[evt.Address]++
+				counts.Ready++
 			case event.ConnectionClosed:
 				monitor.connsPerServer[evt.Address]--
+				counts.Closed++
+				counts.ClosedByReason[evt.Reason]++
+			case event.ConnectionCheckedOut:
+				counts.CheckedOut++
+			case event.ConnectionCheckedIn:
+				counts.CheckedIn++
+			case event.ConnectionCheckOutFailed:
+				counts.CheckOutFailed++
+				counts.CheckOutFailedByReason[evt.Reason]++
+			case event.ConnectionPoolCleared:
+				counts.Cleared++
+				if evt.Interruption {
+					counts.ClearedInterruptingInUse++
+				}
+
+				key := newPoolKey(evt.Address, evt.ServiceID)
+				monitor.generations[key]++
+
+				monitor.clears = append(monitor.clears, PoolClear{
+					Time:           time.Now(),
+					Address:        evt.Address,
+					ServiceID:      evt.ServiceID,
+					Generation:     monitor.generations[key],
+					InterruptInUse: evt.Interruption,
+				})
 			}
 		},
 	}
@@ -45,3 +137,63 @@ func (pm *PoolMonitor) ConnsReady(serverAddr string) int {
 
 	return pm.connsPerServer[serverAddr]
 }
+
+// Counts returns the pool event counts for the given server address.
+func (pm *PoolMonitor) Counts(serverAddr string) PoolCounts {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	counts, ok := pm.counts[serverAddr]
+	if !ok {
+		return PoolCounts{ClosedByReason: map[string]int{}, CheckOutFailedByReason: map[string]int{}}
+	}
+
+	c := *counts
+	c.ClosedByReason = maps.Clone(counts.ClosedByReason)
+	c.CheckOutFailedByReason = maps.Clone(counts.CheckOutFailedByReason)
+
+	return c
+}
+
+// Addresses returns the sorted addresses of the servers with pool events.
+func (pm *PoolMonitor) Addresses() []string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(pm.counts))
+}
+
+// Clears returns the pool clears of the given server address, for every
+// service ID, in order.
+func (pm *PoolMonitor) Clears(serverAddr string) []PoolClear {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	var clears []PoolClear
+	for _, c := range pm.clears {
+		if c.Address == serverAddr {
+			clears = append(clears, c)
+		}
+	}
+
+	return clears
+}
+
+// Generation returns the pool generation of the given server address and
+// service ID, i.e. the number of times it has been cleared. Pass a nil
+// serviceID outside load balanced deployments.
+func (pm *PoolMonitor) Generation(serverAddr string, serviceID *bson.ObjectID) uint64 {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	return pm.generations[newPoolKey(serverAddr, serviceID)]
+}
+
+func newPoolKey(addr string, serviceID *bson.ObjectID) poolKey {
+	key := poolKey{addr: addr}
+	if serviceID != nil {
+		key.serviceID = *serviceID
+	}
+
+	return key
+}
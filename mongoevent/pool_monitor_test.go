@@ -0,0 +1,90 @@
+package mongoevent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func clearPool(em *event.PoolMonitor, addr string, serviceID *bson.ObjectID, interrupt bool) {
+	em.Event(&event.PoolEvent{
+		Type:         event.ConnectionPoolCleared,
+		Address:      addr,
+		ServiceID:    serviceID,
+		Interruption: interrupt,
+	})
+}
+
+func TestPoolMonitorGeneration(t *testing.T) {
+	pm := NewPoolMonitor()
+	em := NewPoolEventMonitor(pm)
+
+	const other = "localhost:27018"
+
+	assert.Zero(t, pm.Generation(testAddr, nil))
+
+	clearPool(em, testAddr, nil, false)
+	clearPool(em, testAddr, nil, true)
+	clearPool(em, other, nil, false)
+
+	assert.EqualValues(t, 2, pm.Generation(testAddr, nil))
+	assert.EqualValues(t, 1, pm.Generation(other, nil))
+
+	counts := pm.Counts(testAddr)
+	assert.Equal(t, 2, counts.Cleared)
+	assert.Equal(t, 1, counts.ClearedInterruptingInUse)
+}
+
+func TestPoolMonitorGenerationPerService(t *testing.T) {
+	pm := NewPoolMonitor()
+	em := NewPoolEventMonitor(pm)
+
+	svc1, svc2 := bson.NewObjectID(), bson.NewObjectID()
+
+	clearPool(em, testAddr, &svc1, false)
+	clearPool(em, testAddr, &svc2, true)
+	clearPool(em, testAddr, &svc1, false)
+
+	assert.EqualValues(t, 2, pm.Generation(testAddr, &svc1))
+	assert.EqualValues(t, 1, pm.Generation(testAddr, &svc2))
+	assert.Zero(t, pm.Generation(testAddr, nil))
+}
+
+func TestPoolMonitorClears(t *testing.T) {
+	pm := NewPoolMonitor()
+	em := NewPoolEventMonitor(pm)
+
+	svc := bson.NewObjectID()
+
+	clearPool(em, testAddr, nil, false)
+	clearPool(em, "localhost:27018", nil, false)
+	clearPool(em, testAddr, &svc, true)
+	clearPool(em, testAddr, nil, true)
+
+	assert.Empty(t, pm.Clears("localhost:27019"))
+
+	clears := pm.Clears(testAddr)
+	require.Len(t, clears, 3)
+
+	for _, c := range clears {
+		assert.Equal(t, testAddr, c.Address)
+	}
+
+	assert.Nil(t, clears[0].ServiceID)
+	assert.EqualValues(t, 1, clears[0].Generation)
+	assert.False(t, clears[0].InterruptInUse)
+
+	require.NotNil(t, clears[1].ServiceID)
+	assert.Equal(t, svc, *clears[1].ServiceID)
+	assert.EqualValues(t, 1, clears[1].Generation)
+	assert.True(t, clears[1].InterruptInUse)
+
+	assert.Nil(t, clears[2].ServiceID)
+	assert.EqualValues(t, 2, clears[2].Generation)
+	assert.True(t, clears[2].InterruptInUse)
+
+	assert.False(t, clears[2].Time.Before(clears[0].Time))
+}
@@ -1,20 +1,63 @@
 package mongoevent
 
 import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
 	"sync"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/event"
 )
 
+// rttAlpha is the weight of a new sample in the RTT moving average, as in
+// the server discovery and monitoring spec.
+const rttAlpha = 0.2
+
+// rttSamples is how many recent samples RTT90 is computed over.
+const rttSamples = 10
+
 // ServerMonitor is a monitor that captures server monitoring events.
 type ServerMonitor struct {
 	mu             sync.RWMutex
 	latestTopology event.TopologyDescription
+	history        []TopologyChange
+	servers        map[string][]ServerChange
+	// changed is closed, and replaced, whenever the topology or a server
+	// description changes.
+	changed chan struct{}
+	rtts    map[string]*rttStats
+}
+
+// TopologyChange is a captured TopologyDescriptionChangedEvent.
+type TopologyChange struct {
+	Time     time.Time
+	Previous event.TopologyDescription
+	New      event.TopologyDescription
+}
+
+// ServerChange is a captured ServerDescriptionChangedEvent.
+type ServerChange struct {
+	Time     time.Time
+	Address  string
+	Previous event.ServerDescription
+	New      event.ServerDescription
+}
+
+// rttStats is the round trip time state of one server.
+type rttStats struct {
+	average time.Duration
+	samples []time.Duration // the most recent rttSamples, oldest first
 }
 
 // NewServerMontior creates a new ServerMonitor.
 func NewServerMontior() *ServerMonitor {
-	return &ServerMonitor{}
+	return &ServerMonitor{
+		servers: make(map[string][]ServerChange),
+		changed: make(chan struct{}),
+		rtts:    make(map[string]*rttStats),
+	}
 }
 
 // NewEventServerMonitor creates an event.ServerMonitor that routes events to
@@ -23,8 +66,57 @@ func NewEventServerMonitor(monitor *ServerMonitor) *event.ServerMonitor {
 	return &event.ServerMonitor{
 		TopologyDescriptionChanged: func(evt *event.TopologyDescriptionChangedEvent) {
 			monitor.mu.Lock()
+			defer monitor.mu.Unlock()
+
 			monitor.latestTopology = evt.NewDescription
-			monitor.mu.Unlock()
+			monitor.history = append(monitor.history, TopologyChange{
+				Time:     time.Now(),
+				Previous: evt.PreviousDescription,
+				New:      evt.NewDescription,
+			})
+
+			close(monitor.changed)
+			monitor.changed = make(chan struct{})
+		},
+		ServerDescriptionChanged: func(evt *event.ServerDescriptionChangedEvent) {
+			monitor.mu.Lock()
+			defer monitor.mu.Unlock()
+
+			addr := evt.Address.String()
+			monitor.servers[addr] = append(monitor.servers[addr], ServerChange{
+				Time:     time.Now(),
+				Address:  addr,
+				Previous: evt.PreviousDescription,
+				New:      evt.NewDescription,
+			})
+
+			close(monitor.changed)
+			monitor.changed = make(chan struct{})
+		},
+		ServerHeartbeatSucceeded: func(evt *event.ServerHeartbeatSucceededEvent) {
+			// Awaited (streaming) heartbeats wait for a topology change, so
+			// their duration isn't a round trip time.
+			if evt.Awaited {
+				return
+			}
+
+			monitor.mu.Lock()
+			defer monitor.mu.Unlock()
+
+			addr := ConnectionAddress(evt.ConnectionID)
+
+			stats := monitor.rtts[addr]
+			if stats == nil {
+				stats = &rttStats{average: evt.Duration}
+				monitor.rtts[addr] = stats
+			} else {
+				stats.average = time.Duration(rttAlpha*float64(evt.Duration) + (1-rttAlpha)*float64(stats.average))
+			}
+
+			stats.samples = append(stats.samples, evt.Duration)
+			if len(stats.samples) > rttSamples {
+				stats.samples = stats.samples[1:]
+			}
 		},
 	}
 }
@@ -37,3 +129,129 @@ func (sm *ServerMonitor) LatestTopologyDescription() event.TopologyDescription {
 
 	return sm.latestTopology
 }
+
+// History returns a copy of the captured topology description changes in
+// order, e.g. to assert on the sequence of kinds through an election.
+func (sm *ServerMonitor) History() []TopologyChange {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return append([]TopologyChange(nil), sm.history...)
+}
+
+// WaitForTopologyKind blocks until the latest topology description is of the
+// given kind (e.g. "ReplicaSetWithPrimary") and returns it. It returns ctx's
+// error if ctx is done first.
+func (sm *ServerMonitor) WaitForTopologyKind(ctx context.Context, kind string) (event.TopologyDescription, error) {
+	for {
+		sm.mu.RLock()
+		latest, changed := sm.latestTopology, sm.changed
+		sm.mu.RUnlock()
+
+		if latest.Kind == kind {
+			return latest, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return event.TopologyDescription{}, fmt.Errorf("wait for topology kind %q, last %q: %w", kind, latest.Kind, ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+// ServerTimeline returns a copy of the captured description changes of the
+// server at serverAddr in order.
+func (sm *ServerMonitor) ServerTimeline(serverAddr string) []ServerChange {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return append([]ServerChange(nil), sm.servers[serverAddr]...)
+}
+
+// ServerStateAt returns the description the server at serverAddr had at time
+// at, i.e. the new description of its last change at or before at. It
+// reports false if the server had no captured description then.
+func (sm *ServerMonitor) ServerStateAt(serverAddr string, at time.Time) (event.ServerDescription, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	changes := sm.servers[serverAddr]
+	for i := len(changes) - 1; i >= 0; i-- {
+		if !changes[i].Time.After(at) {
+			return changes[i].New, true
+		}
+	}
+
+	return event.ServerDescription{}, false
+}
+
+// WaitForServerKind blocks until the latest description of the server at
+// serverAddr is of the given kind (e.g. "RSPrimary" or "Unknown") and
+// returns it. It returns ctx's error if ctx is done first.
+func (sm *ServerMonitor) WaitForServerKind(ctx context.Context, serverAddr, kind string) (event.ServerDescription, error) {
+	for {
+		sm.mu.RLock()
+
+		var latest event.ServerDescription
+		if changes := sm.servers[serverAddr]; len(changes) > 0 {
+			latest = changes[len(changes)-1].New
+		}
+
+		changed := sm.changed
+		sm.mu.RUnlock()
+
+		if latest.Kind == kind {
+			return latest, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return event.ServerDescription{}, fmt.Errorf("wait for server %s kind %q, last %q: %w", serverAddr, kind, latest.Kind, ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+// RTT returns the moving average of the round trip times observed by the
+// heartbeats to serverAddr, computed like the driver's, or 0 if there were
+// none.
+func (sm *ServerMonitor) RTT(serverAddr string) time.Duration {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if stats := sm.rtts[serverAddr]; stats != nil {
+		return stats.average
+	}
+
+	return 0
+}
+
+// RTT90 returns the 90th percentile of the last 10 heartbeat round trip
+// times to serverAddr, or 0 if there were none.
+func (sm *ServerMonitor) RTT90(serverAddr string) time.Duration {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	stats := sm.rtts[serverAddr]
+	if stats == nil || len(stats.samples) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(stats.samples)
+	slices.Sort(sorted)
+
+	rank := (90*len(sorted) + 99) / 100 // nearest rank
+
+	return sorted[rank-1]
+}
+
+// connectionIDSuffix matches the "[-N]" the driver appends to a server
+// address to form a connection ID.
+var connectionIDSuffix = regexp.MustCompile(`\[-\d+\]$`)
+
+// ConnectionAddress returns the server address of a driver connection ID, as
+// found in command and heartbeat events (e.g. "localhost:27017[-3]").
+func ConnectionAddress(connectionID string) string {
+	return connectionIDSuffix.ReplaceAllString(connectionID, "")
+}
@@ -0,0 +1,156 @@
+package mongoevent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+const testAddr = "localhost:27017"
+
+func heartbeat(em *event.ServerMonitor, d time.Duration, awaited bool) {
+	em.ServerHeartbeatSucceeded(&event.ServerHeartbeatSucceededEvent{
+		Duration:     d,
+		ConnectionID: testAddr + "[-1]",
+		Awaited:      awaited,
+	})
+}
+
+func TestServerMonitorRTT(t *testing.T) {
+	sm := NewServerMontior()
+	em := NewEventServerMonitor(sm)
+
+	assert.Zero(t, sm.RTT(testAddr))
+
+	// The first sample seeds the average; later ones are weighted by 0.2.
+	heartbeat(em, 10*time.Millisecond, false)
+	assert.Equal(t, 10*time.Millisecond, sm.RTT(testAddr))
+
+	heartbeat(em, 20*time.Millisecond, false)
+	assert.InDelta(t, 12*time.Millisecond, sm.RTT(testAddr), float64(time.Microsecond))
+
+	heartbeat(em, 30*time.Millisecond, false)
+	assert.InDelta(t, 15600*time.Microsecond, sm.RTT(testAddr), float64(time.Microsecond))
+
+	// Awaited heartbeats aren't round trips.
+	heartbeat(em, time.Minute, true)
+	assert.InDelta(t, 15600*time.Microsecond, sm.RTT(testAddr), float64(time.Microsecond))
+
+	assert.Zero(t, sm.RTT("other:27017"))
+}
+
+func TestServerMonitorRTT90(t *testing.T) {
+	t.Run("no samples", func(t *testing.T) {
+		sm := NewServerMontior()
+
+		assert.Zero(t, sm.RTT90(testAddr))
+	})
+
+	t.Run("nearest rank", func(t *testing.T) {
+		sm := NewServerMontior()
+		em := NewEventServerMonitor(sm)
+
+		heartbeat(em, 30*time.Millisecond, false)
+		assert.Equal(t, 30*time.Millisecond, sm.RTT90(testAddr))
+
+		heartbeat(em, 10*time.Millisecond, false)
+		heartbeat(em, 20*time.Millisecond, false)
+
+		// ceil(0.9 * 3) = 3rd of 3.
+		assert.Equal(t, 30*time.Millisecond, sm.RTT90(testAddr))
+	})
+
+	t.Run("window", func(t *testing.T) {
+		sm := NewServerMontior()
+		em := NewEventServerMonitor(sm)
+
+		// Outliers that fall out of the 10-sample window.
+		heartbeat(em, 200*time.Millisecond, false)
+		heartbeat(em, 100*time.Millisecond, false)
+
+		for i := 10; i >= 1; i-- {
+			heartbeat(em, time.Duration(i)*time.Millisecond, false)
+		}
+
+		heartbeat(em, time.Minute, true)
+
+		// ceil(0.9 * 10) = 9th of 1ms..10ms.
+		assert.Equal(t, 9*time.Millisecond, sm.RTT90(testAddr))
+	})
+}
+
+func changeTopology(em *event.ServerMonitor, from, to string) {
+	em.TopologyDescriptionChanged(&event.TopologyDescriptionChangedEvent{
+		PreviousDescription: event.TopologyDescription{Kind: from},
+		NewDescription:      event.TopologyDescription{Kind: to},
+	})
+}
+
+func TestServerMonitorHistory(t *testing.T) {
+	sm := NewServerMontior()
+	em := NewEventServerMonitor(sm)
+
+	assert.Empty(t, sm.History())
+
+	changeTopology(em, "Unknown", "ReplicaSetNoPrimary")
+	changeTopology(em, "ReplicaSetNoPrimary", "ReplicaSetWithPrimary")
+
+	history := sm.History()
+	require.Len(t, history, 2)
+
+	assert.Equal(t, "Unknown", history[0].Previous.Kind)
+	assert.Equal(t, "ReplicaSetNoPrimary", history[0].New.Kind)
+	assert.Equal(t, "ReplicaSetNoPrimary", history[1].Previous.Kind)
+	assert.Equal(t, "ReplicaSetWithPrimary", history[1].New.Kind)
+	assert.False(t, history[1].Time.Before(history[0].Time))
+
+	assert.Equal(t, "ReplicaSetWithPrimary", sm.LatestTopologyDescription().Kind)
+
+	// History returns a copy.
+	history[0].New.Kind = "Sharded"
+	assert.Equal(t, "ReplicaSetNoPrimary", sm.History()[0].New.Kind)
+}
+
+func TestServerMonitorWaitForTopologyKind(t *testing.T) {
+	t.Run("already", func(t *testing.T) {
+		sm := NewServerMontior()
+		changeTopology(NewEventServerMonitor(sm), "Unknown", "Single")
+
+		td, err := sm.WaitForTopologyKind(context.Background(), "Single")
+		require.NoError(t, err)
+		assert.Equal(t, "Single", td.Kind)
+	})
+
+	t.Run("later", func(t *testing.T) {
+		sm := NewServerMontior()
+		em := NewEventServerMonitor(sm)
+
+		go func() {
+			changeTopology(em, "Unknown", "ReplicaSetNoPrimary")
+			changeTopology(em, "ReplicaSetNoPrimary", "ReplicaSetWithPrimary")
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		td, err := sm.WaitForTopologyKind(ctx, "ReplicaSetWithPrimary")
+		require.NoError(t, err)
+		assert.Equal(t, "ReplicaSetWithPrimary", td.Kind)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		sm := NewServerMontior()
+		changeTopology(NewEventServerMonitor(sm), "Unknown", "ReplicaSetNoPrimary")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := sm.WaitForTopologyKind(ctx, "ReplicaSetWithPrimary")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, `last "ReplicaSetNoPrimary"`)
+	})
+}
@@ -5,10 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prestonvasquez/go-playground/atlaslocal"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // DefMappings represents the mappings for an index definition.
@@ -21,29 +20,63 @@ type Def struct {
 	Mappings DefMappings
 }
 
-// AwaitIndex waits for a search index with the given name to become queryable.
-func AwaitIndex(t *testing.T, ctx context.Context, siv mongo.SearchIndexView, searchName string) bson.Raw {
+// AwaitIndex waits for a search index with the given name to become queryable,
+// failing the test if it can't. It waits until ctx's deadline, or for the
+// 30s default of atlaslocal.WithAwaitTimeout if ctx has none. See
+// AwaitIndexCtx.
+func AwaitIndex(t *testing.T, ctx context.Context, siv atlaslocal.SearchIndexLister, searchName string) bson.Raw {
 	t.Helper()
 
-	// Await the creation of the index.
-	var doc bson.Raw
-	for doc == nil {
-		cursor, err := siv.List(ctx, options.SearchIndexes().SetName(searchName))
-		require.NoError(t, err)
+	var opts []AwaitOption
+	if deadline, ok := ctx.Deadline(); ok {
+		opts = append(opts, WithTimeout(time.Until(deadline)))
+	}
+
+	doc, err := AwaitIndexCtx(ctx, siv, searchName, opts...)
+	require.NoError(t, err)
+
+	return doc
+}
 
-		if !cursor.Next(ctx) {
-			break
-		}
+// AwaitOption configures AwaitIndexCtx.
+type AwaitOption func(*awaitOptions)
 
-		name := cursor.Current.Lookup("name").StringValue()
-		queryable := cursor.Current.Lookup("queryable").Boolean()
+type awaitOptions struct {
+	awaitOpts []atlaslocal.AwaitOption
+}
 
-		if name == searchName && queryable {
-			doc = cursor.Current
-		} else {
-			time.Sleep(100 * time.Millisecond)
-		}
+// WithInterval sets the delay before the first re-check of the index, which
+// doubles after every check up to 2s, or d if that is larger. See
+// atlaslocal.WithAwaitBackoff for the defaults.
+func WithInterval(d time.Duration) AwaitOption {
+	return func(o *awaitOptions) {
+		o.awaitOpts = append(o.awaitOpts, atlaslocal.WithAwaitBackoff(d, 0))
 	}
+}
 
-	return doc
+// WithTimeout bounds the wait, in addition to the context's deadline. See
+// atlaslocal.WithAwaitTimeout for the default.
+func WithTimeout(d time.Duration) AwaitOption {
+	return func(o *awaitOptions) {
+		o.awaitOpts = append(o.awaitOpts, atlaslocal.WithAwaitTimeout(d))
+	}
+}
+
+// AwaitIndexCtx waits for a search index with the given name to become
+// queryable and returns its description. It is atlaslocal.AwaitSearchIndex
+// with atlaslocal.WithRequireQueryable, so it checks with exponential backoff
+// until the index is queryable, the index build fails, listing the indexes
+// fails, or the wait times out.
+func AwaitIndexCtx(ctx context.Context, siv atlaslocal.SearchIndexLister, searchName string, opts ...AwaitOption) (bson.Raw, error) {
+	cfg := awaitOptions{awaitOpts: []atlaslocal.AwaitOption{atlaslocal.WithRequireQueryable()}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	status, err := atlaslocal.AwaitSearchIndex(ctx, siv, searchName, cfg.awaitOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return status.Raw, nil
 }
@@ -0,0 +1,109 @@
+package mongoindex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// fakeLister lists one entry of listings per call, repeating the last one,
+// and records when it was called. A nil listing lists no index.
+type fakeLister struct {
+	listings []bson.D
+	err      error
+	calls    []time.Time
+}
+
+func (f *fakeLister) List(
+	context.Context,
+	options.Lister[options.SearchIndexesOptions],
+	...options.Lister[options.ListSearchIndexesOptions],
+) (*mongo.Cursor, error) {
+	f.calls = append(f.calls, time.Now())
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var docs []any
+	if listing := f.listings[min(len(f.calls), len(f.listings))-1]; listing != nil {
+		docs = append(docs, listing)
+	}
+
+	return mongo.NewCursorFromDocuments(docs, nil, nil)
+}
+
+func index(status string, queryable bool) bson.D {
+	return bson.D{
+		{Key: "name", Value: "default"},
+		{Key: "status", Value: status},
+		{Key: "queryable", Value: queryable},
+	}
+}
+
+func TestAwaitIndexCtxBacksOff(t *testing.T) {
+	const interval = 5 * time.Millisecond
+
+	lister := &fakeLister{listings: []bson.D{nil, index("PENDING", false), index("BUILDING", false), index("READY", true)}}
+
+	doc, err := AwaitIndexCtx(context.Background(), lister, "default", WithInterval(interval))
+	require.NoError(t, err)
+
+	assert.Equal(t, "READY", doc.Lookup("status").StringValue())
+	require.Len(t, lister.calls, 4)
+
+	// Each re-check waits at least twice as long as the one before it.
+	for i := 1; i < len(lister.calls); i++ {
+		want := interval << (i - 1)
+		assert.GreaterOrEqual(t, lister.calls[i].Sub(lister.calls[i-1]), want, "gap before check %d", i)
+	}
+}
+
+func TestAwaitIndexCtxTimeout(t *testing.T) {
+	lister := &fakeLister{listings: []bson.D{index("PENDING", false)}}
+
+	start := time.Now()
+
+	_, err := AwaitIndexCtx(context.Background(), lister, "default",
+		WithInterval(time.Millisecond), WithTimeout(50*time.Millisecond))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Greater(t, len(lister.calls), 1)
+}
+
+func TestAwaitIndexCtxContextCanceled(t *testing.T) {
+	lister := &fakeLister{listings: []bson.D{nil}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := AwaitIndexCtx(ctx, lister, "default", WithInterval(time.Millisecond))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAwaitIndexCtxFailedBuild(t *testing.T) {
+	lister := &fakeLister{listings: []bson.D{index("FAILED", false)}}
+
+	_, err := AwaitIndexCtx(context.Background(), lister, "default")
+	require.ErrorContains(t, err, "failed to build")
+
+	assert.Len(t, lister.calls, 1)
+}
+
+func TestAwaitIndexCtxListError(t *testing.T) {
+	listErr := errors.New("list failed")
+	lister := &fakeLister{err: listErr}
+
+	_, err := AwaitIndexCtx(context.Background(), lister, "default")
+	require.ErrorIs(t, err, listErr)
+
+	assert.Len(t, lister.calls, 1)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/go-connections/nat"
 	"github.com/google/uuid"
+	"github.com/prestonvasquez/go-playground/monitor"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/mongodb"
@@ -48,6 +49,7 @@ type options struct {
 	cryptSharedLibPath   string // crypt_shared library path for auto-encryption
 	hostPort             int    // 0 = let testcontainers pick a free port
 	containerName        string // empty = let testcontainers generate one
+	recorder             *monitor.Recorder
 
 	// extraContainerOpts is populated internally for cases (like OIDC) where
 	// callers need to inject testcontainers customizers that depend on
@@ -392,6 +394,10 @@ func startContainerT(t *testing.T, ctx context.Context, optionFuncs ...Option) *
 	require.False(t, opts.mongoClientOpts != nil && opts.mongoClientOptsV1 != nil,
 		"mongo.Client options v1 and v2 cannot both be set")
 
+	// The recorder only monitors v2 clients.
+	require.False(t, opts.recorder != nil && opts.mongoClientOptsV1 != nil,
+		"a recorder cannot be used with the v1 mongo.Client")
+
 	// OIDC requires a replica set.
 	require.True(t, opts.oidcConfig == nil || opts.oidcConfig != nil, "OIDC requires using a replica set")
 
@@ -455,6 +461,10 @@ func startContainerT(t *testing.T, ctx context.Context, optionFuncs ...Option) *
 		mopts = mopts.ApplyURI(connString)
 	}
 
+	if opts.recorder != nil {
+		mopts = installRecorder(mopts, opts.recorder)
+	}
+
 	moptsV1 := opts.mongoClientOptsV1
 	if moptsV1 != nil {
 		// v1 only applies if explicitly requested.
@@ -0,0 +1,99 @@
+package mongolocal
+
+import (
+	"context"
+
+	"github.com/prestonvasquez/go-playground/monitor"
+	"go.mongodb.org/mongo-driver/v2/event"
+
+	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// WithRecorder installs r's command, pool, and server monitors on the v2
+// client. Monitors already set with WithMongoClientOptions keep working: their
+// callbacks run after r's. The options passed to WithMongoClientOptions are
+// left untouched, so they can be reused.
+//
+// This cannot be used with the v1 client.
+func WithRecorder(r *monitor.Recorder) Option {
+	return func(o *options) {
+		o.recorder = r
+	}
+}
+
+// installRecorder returns a shallow copy of opts with r's monitors set,
+// merged with the ones opts has. Neither opts nor r is modified.
+func installRecorder(opts *mongooptions.ClientOptions, r *monitor.Recorder) *mongooptions.ClientOptions {
+	cp := *opts
+	opts = &cp
+
+	cmd := r.CommandMonitor()
+	if user := opts.Monitor; user != nil {
+		cmd = &event.CommandMonitor{
+			Started:   bothCtx(cmd.Started, user.Started),
+			Succeeded: bothCtx(cmd.Succeeded, user.Succeeded),
+			Failed:    bothCtx(cmd.Failed, user.Failed),
+		}
+	}
+
+	opts.SetMonitor(cmd)
+
+	pool := r.PoolMonitor()
+	if user := opts.PoolMonitor; user != nil {
+		pool = &event.PoolMonitor{Event: both(pool.Event, user.Event)}
+	}
+
+	opts.SetPoolMonitor(pool)
+
+	server := r.ServerMonitor()
+	if user := opts.ServerMonitor; user != nil {
+		server = &event.ServerMonitor{
+			ServerDescriptionChanged:   both(server.ServerDescriptionChanged, user.ServerDescriptionChanged),
+			ServerOpening:              both(server.ServerOpening, user.ServerOpening),
+			ServerClosed:               both(server.ServerClosed, user.ServerClosed),
+			TopologyDescriptionChanged: both(server.TopologyDescriptionChanged, user.TopologyDescriptionChanged),
+			TopologyOpening:            both(server.TopologyOpening, user.TopologyOpening),
+			TopologyClosed:             both(server.TopologyClosed, user.TopologyClosed),
+			ServerHeartbeatStarted:     both(server.ServerHeartbeatStarted, user.ServerHeartbeatStarted),
+			ServerHeartbeatSucceeded:   both(server.ServerHeartbeatSucceeded, user.ServerHeartbeatSucceeded),
+			ServerHeartbeatFailed:      both(server.ServerHeartbeatFailed, user.ServerHeartbeatFailed),
+		}
+	}
+
+	opts.SetServerMonitor(server)
+
+	return opts
+}
+
+// both returns a callback running first and then second, either of which
+// may be nil.
+func both[E any](first, second func(E)) func(E) {
+	if first == nil {
+		return second
+	}
+
+	if second == nil {
+		return first
+	}
+
+	return func(evt E) {
+		first(evt)
+		second(evt)
+	}
+}
+
+// bothCtx is both for command monitor callbacks.
+func bothCtx[E any](first, second func(context.Context, E)) func(context.Context, E) {
+	if first == nil {
+		return second
+	}
+
+	if second == nil {
+		return first
+	}
+
+	return func(ctx context.Context, evt E) {
+		first(ctx, evt)
+		second(ctx, evt)
+	}
+}
@@ -0,0 +1,67 @@
+package mongolocal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prestonvasquez/go-playground/monitor"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/event"
+
+	mongooptions "go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestInstallRecorderMergesMonitors(t *testing.T) {
+	var userCmds, userPool, userTopology int
+
+	opts := mongooptions.Client().
+		SetMonitor(&event.CommandMonitor{
+			Started: func(context.Context, *event.CommandStartedEvent) { userCmds++ },
+		}).
+		SetPoolMonitor(&event.PoolMonitor{
+			Event: func(*event.PoolEvent) { userPool++ },
+		}).
+		SetServerMonitor(&event.ServerMonitor{
+			TopologyDescriptionChanged: func(*event.TopologyDescriptionChangedEvent) { userTopology++ },
+		})
+
+	r := monitor.NewRecorder()
+	opts = installRecorder(opts, r)
+
+	opts.Monitor.Started(context.Background(), &event.CommandStartedEvent{CommandName: "find"})
+	opts.PoolMonitor.Event(&event.PoolEvent{Type: event.ConnectionCheckedOut})
+	opts.ServerMonitor.TopologyDescriptionChanged(&event.TopologyDescriptionChangedEvent{})
+
+	assert.Len(t, r.CommandStartedEvents(), 1)
+	assert.Len(t, r.ConnectionCheckedOutEvents(), 1)
+	assert.Len(t, r.TopologyDescriptionChangedEvents(), 1)
+
+	assert.Equal(t, 1, userCmds)
+	assert.Equal(t, 1, userPool)
+	assert.Equal(t, 1, userTopology)
+}
+
+func TestInstallRecorderLeavesOptionsUntouched(t *testing.T) {
+	var userCmds int
+
+	user := &event.CommandMonitor{
+		Started: func(context.Context, *event.CommandStartedEvent) { userCmds++ },
+	}
+
+	opts := mongooptions.Client().SetMonitor(user)
+	r := monitor.NewRecorder()
+
+	// Reusing the same options and recorder must not chain the recorder into
+	// itself or run the user's monitor twice.
+	installRecorder(opts, r)
+	merged := installRecorder(opts, r)
+
+	assert.Same(t, user, opts.Monitor)
+	assert.Nil(t, opts.PoolMonitor)
+	assert.Nil(t, opts.ServerMonitor)
+
+	merged.Monitor.Started(context.Background(), &event.CommandStartedEvent{CommandName: "find"})
+
+	assert.Len(t, r.CommandStartedEvents(), 1)
+	assert.Equal(t, 1, userCmds)
+}
@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startedCommandNames returns the names of the started commands in order.
+func (r *Recorder) startedCommandNames() []string {
+	var names []string
+	for _, evt := range r.CommandStartedEvents() {
+		names = append(names, evt.CommandName)
+	}
+
+	return names
+}
+
+// AssertCommandsInOrder fails the test unless the named commands were
+// started in the given order. Other commands may be interleaved, so commands
+// the test doesn't care about (e.g. from a shared client) don't break it.
+func (r *Recorder) AssertCommandsInOrder(t *testing.T, cmds ...string) {
+	t.Helper()
+
+	require.NoError(t, checkInOrder(r.startedCommandNames(), cmds))
+}
+
+// checkInOrder returns an error naming the first of cmds not found, in order,
+// in started.
+func checkInOrder(started, cmds []string) error {
+	next := 0
+	for _, name := range started {
+		if next < len(cmds) && name == cmds[next] {
+			next++
+		}
+	}
+
+	if next == len(cmds) {
+		return nil
+	}
+
+	return fmt.Errorf("expected commands %v in order; %q not found after %v in started commands %v",
+		cmds, cmds[next], cmds[:next], started)
+}
+
+// AssertCommandCount fails the test unless cmd was started exactly n times.
+func (r *Recorder) AssertCommandCount(t *testing.T, cmd string, n int) {
+	t.Helper()
+
+	require.NoError(t, checkCount(r.startedCommandNames(), cmd, n))
+}
+
+// AssertNoCommand fails the test if cmd was started.
+func (r *Recorder) AssertNoCommand(t *testing.T, cmd string) {
+	t.Helper()
+
+	require.NoError(t, checkCount(r.startedCommandNames(), cmd, 0))
+}
+
+// checkCount returns an error unless cmd appears n times in started.
+func checkCount(started []string, cmd string, n int) error {
+	count := 0
+	for _, name := range started {
+		if name == cmd {
+			count++
+		}
+	}
+
+	switch {
+	case count == n:
+		return nil
+	case n == 0:
+		return fmt.Errorf("expected no %q command, got %d in started commands %v", cmd, count, started)
+	default:
+		return fmt.Errorf("expected %q to be started %d times, got %d", cmd, n, count)
+	}
+}
@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func TestCheckInOrder(t *testing.T) {
+	started := []string{"hello", "find", "ping", "getMore", "find", "killCursors"}
+
+	for _, tc := range []struct {
+		name    string
+		cmds    []string
+		wantErr string
+	}{
+		{name: "none", cmds: nil},
+		{name: "all", cmds: started},
+		{name: "interleaved", cmds: []string{"find", "getMore", "killCursors"}},
+		{name: "repeated", cmds: []string{"find", "find"}},
+		{name: "last command", cmds: []string{"killCursors"}},
+		{
+			name:    "out of order",
+			cmds:    []string{"getMore", "hello"},
+			wantErr: `"hello" not found after [getMore]`,
+		},
+		{
+			name:    "missing first",
+			cmds:    []string{"insert", "find"},
+			wantErr: `"insert" not found after []`,
+		},
+		{
+			name:    "missing last",
+			cmds:    []string{"find", "getMore", "insert"},
+			wantErr: `"insert" not found after [find getMore]`,
+		},
+		{
+			name:    "repeated too often",
+			cmds:    []string{"find", "find", "find"},
+			wantErr: `"find" not found after [find find]`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkInOrder(started, tc.cmds)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestCheckCount(t *testing.T) {
+	started := []string{"find", "getMore", "find"}
+
+	assert.NoError(t, checkCount(started, "find", 2))
+	assert.NoError(t, checkCount(started, "insert", 0))
+	assert.ErrorContains(t, checkCount(started, "find", 1), `expected "find" to be started 1 times, got 2`)
+	assert.ErrorContains(t, checkCount(started, "getMore", 0), `expected no "getMore" command, got 1`)
+}
+
+func TestRecorderAssertions(t *testing.T) {
+	r := NewRecorder()
+
+	for i, name := range []string{"find", "getMore", "getMore", "killCursors"} {
+		r.CommandMonitor().Started(context.Background(), &event.CommandStartedEvent{
+			CommandName: name,
+			RequestID:   int64(i),
+		})
+	}
+
+	require.Equal(t, []string{"find", "getMore", "getMore", "killCursors"}, r.startedCommandNames())
+
+	r.AssertCommandsInOrder(t, "find", "killCursors")
+	r.AssertCommandCount(t, "getMore", 2)
+	r.AssertNoCommand(t, "insert")
+}
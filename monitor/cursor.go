@@ -0,0 +1,144 @@
+package monitor
+
+import (
+	"errors"
+
+	"github.com/prestonvasquez/go-playground/failpoint/codes"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver"
+)
+
+// Cursor is a server cursor opened by a recorded command.
+type Cursor struct {
+	ID        int64
+	Namespace string
+
+	// Command is the name of the command that opened the cursor, e.g. find
+	// or aggregate, and ConnectionID the connection it ran on.
+	Command      string
+	ConnectionID string
+
+	// GetMores counts the getMores run on the cursor.
+	GetMores int
+
+	// LastErr is the failure of the last getMore, or nil if it succeeded.
+	LastErr error
+}
+
+// OpenCursors returns the cursors opened by recorded commands that weren't
+// since exhausted, killed, or found missing by the server, in the order they
+// were opened. Cursors are tracked from the replies of the commands that
+// open them (find, aggregate, ...) and of getMore and killCursors, so all of
+// these must be recorded (see WithCommands).
+func (r *Recorder) OpenCursors() []Cursor {
+	var open []Cursor
+	for _, c := range r.cursors() {
+		open = append(open, *c)
+	}
+
+	return open
+}
+
+// LeakedCursors returns the open cursors whose last getMore failed, e.g.
+// timed out, and that weren't killed after: the driver should kill a cursor
+// it gives up on, so these are left open on the server until it times them
+// out.
+func (r *Recorder) LeakedCursors() []Cursor {
+	var leaked []Cursor
+	for _, c := range r.cursors() {
+		if c.LastErr != nil {
+			leaked = append(leaked, *c)
+		}
+	}
+
+	return leaked
+}
+
+// cursors returns the open cursors in the order they were opened.
+func (r *Recorder) cursors() []*Cursor {
+	var opened []*Cursor
+
+	open := map[int64]*Cursor{}
+
+	for _, ex := range r.Correlate() {
+		cmd := ex.Started.Command
+
+		switch ex.Started.CommandName {
+		case "getMore":
+			id, _ := cmd.Lookup("getMore").AsInt64OK()
+
+			c, ok := open[id]
+			if !ok {
+				continue
+			}
+
+			switch {
+			case ex.Succeeded != nil:
+				c.GetMores++
+				c.LastErr = nil
+
+				if replyCursorID(ex.Succeeded.Reply) == 0 {
+					delete(open, id)
+				}
+			case ex.Failed != nil:
+				c.GetMores++
+				c.LastErr = ex.Failed.Failure
+
+				var drvErr driver.Error
+				if errors.As(ex.Failed.Failure, &drvErr) && drvErr.Code == codes.CursorNotFound {
+					delete(open, id)
+				}
+			}
+		case "killCursors":
+			if ex.Succeeded == nil {
+				continue
+			}
+
+			ids, _ := cmd.Lookup("cursors").ArrayOK()
+			values, _ := ids.Values()
+
+			for _, v := range values {
+				if id, ok := v.AsInt64OK(); ok {
+					delete(open, id)
+				}
+			}
+		default:
+			if ex.Succeeded == nil {
+				continue
+			}
+
+			id := replyCursorID(ex.Succeeded.Reply)
+			if id == 0 {
+				continue
+			}
+
+			ns, _ := ex.Succeeded.Reply.Lookup("cursor", "ns").StringValueOK()
+
+			c := &Cursor{
+				ID:           id,
+				Namespace:    ns,
+				Command:      ex.Started.CommandName,
+				ConnectionID: ex.Started.ConnectionID,
+			}
+
+			open[id] = c
+			opened = append(opened, c)
+		}
+	}
+
+	var cursors []*Cursor
+	for _, c := range opened {
+		if open[c.ID] == c {
+			cursors = append(cursors, c)
+		}
+	}
+
+	return cursors
+}
+
+// replyCursorID returns the cursor ID of a cursor reply, or 0 if it has none.
+func replyCursorID(reply bson.Raw) int64 {
+	id, _ := reply.Lookup("cursor", "id").AsInt64OK()
+
+	return id
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver"
+)
+
+func TestRecorderCursors(t *testing.T) {
+	r := NewRecorder()
+	cm := r.CommandMonitor()
+	ctx := context.Background()
+
+	requestID := int64(0)
+	run := func(cmdName string, cmd bson.D, reply bson.D, failure error) {
+		requestID++
+
+		cm.Started(ctx, &event.CommandStartedEvent{CommandName: cmdName, RequestID: requestID, Command: mustMarshal(t, cmd)})
+
+		finished := event.CommandFinishedEvent{CommandName: cmdName, RequestID: requestID}
+		if failure != nil {
+			cm.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: failure})
+		} else {
+			cm.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished, Reply: mustMarshal(t, reply)})
+		}
+	}
+
+	cursorReply := func(id int64) bson.D {
+		return bson.D{{Key: "cursor", Value: bson.D{{Key: "id", Value: id}, {Key: "ns", Value: "db.coll"}}}}
+	}
+
+	getMore := func(id int64) bson.D {
+		return bson.D{{Key: "getMore", Value: id}, {Key: "collection", Value: "coll"}}
+	}
+
+	// Exhausted by a getMore.
+	run("find", bson.D{{Key: "find", Value: "coll"}}, cursorReply(1), nil)
+	run("getMore", getMore(1), cursorReply(0), nil)
+
+	// Killed.
+	run("aggregate", bson.D{{Key: "aggregate", Value: "coll"}}, cursorReply(2), nil)
+	run("killCursors", bson.D{{Key: "killCursors", Value: "coll"}, {Key: "cursors", Value: bson.A{int64(2)}}}, bson.D{}, nil)
+
+	// Missing on the server.
+	run("find", bson.D{{Key: "find", Value: "coll"}}, cursorReply(3), nil)
+	run("getMore", getMore(3), nil, driver.Error{Code: 43, Message: "cursor not found"})
+
+	// Still open.
+	run("find", bson.D{{Key: "find", Value: "coll"}}, cursorReply(4), nil)
+	run("getMore", getMore(4), cursorReply(4), nil)
+
+	// Leaked by a timed out getMore.
+	run("aggregate", bson.D{{Key: "aggregate", Value: "coll"}}, cursorReply(5), nil)
+	run("getMore", getMore(5), nil, context.DeadlineExceeded)
+
+	// Opened without a cursor.
+	run("find", bson.D{{Key: "find", Value: "coll"}}, cursorReply(0), nil)
+
+	open := r.OpenCursors()
+	require.Len(t, open, 2)
+	assert.Equal(t, Cursor{ID: 4, Namespace: "db.coll", Command: "find", GetMores: 1}, open[0])
+	assert.EqualValues(t, 5, open[1].ID)
+
+	leaked := r.LeakedCursors()
+	require.Len(t, leaked, 1)
+	assert.EqualValues(t, 5, leaked[0].ID)
+	assert.Equal(t, "aggregate", leaked[0].Command)
+	assert.ErrorIs(t, leaked[0].LastErr, context.DeadlineExceeded)
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import "go.mongodb.org/mongo-driver/v2/event"
+
+// Exchange is a started command and its outcome. At most one of Succeeded
+// and Failed is set; neither is while the command is in flight or if the
+// outcome wasn't recorded.
+type Exchange struct {
+	Started   *event.CommandStartedEvent
+	Succeeded *event.CommandSucceededEvent
+	Failed    *event.CommandFailedEvent
+}
+
+// ExchangeFor returns the recorded events of the command with the given
+// request ID, as reported by each event's RequestID. It reports false if no
+// started event was recorded for requestID.
+func (r *Recorder) ExchangeFor(requestID int64) (Exchange, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ex Exchange
+	for _, e := range r.ordered() {
+		switch evt := e.Event.(type) {
+		case *event.CommandStartedEvent:
+			if evt.RequestID == requestID {
+				ex.Started = evt
+			}
+		case *event.CommandSucceededEvent:
+			if evt.RequestID == requestID {
+				ex.Succeeded = evt
+			}
+		case *event.CommandFailedEvent:
+			if evt.RequestID == requestID {
+				ex.Failed = evt
+			}
+		}
+	}
+
+	return ex, ex.Started != nil
+}
+
+// Correlate returns an Exchange for every recorded started command, in the
+// order they started, with the outcome of the same request ID on the same
+// connection. Outcomes whose started event isn't recorded (e.g. dropped by
+// WithMaxEvents) are left out.
+func (r *Recorder) Correlate() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type key struct {
+		requestID    int64
+		connectionID string
+	}
+
+	var exchanges []Exchange
+
+	started := map[key]int{} // index into exchanges
+	for _, e := range r.ordered() {
+		switch evt := e.Event.(type) {
+		case *event.CommandStartedEvent:
+			started[key{evt.RequestID, evt.ConnectionID}] = len(exchanges)
+			exchanges = append(exchanges, Exchange{Started: evt})
+		case *event.CommandSucceededEvent:
+			if i, ok := started[key{evt.RequestID, evt.ConnectionID}]; ok {
+				exchanges[i].Succeeded = evt
+			}
+		case *event.CommandFailedEvent:
+			if i, ok := started[key{evt.RequestID, evt.ConnectionID}]; ok {
+				exchanges[i].Failed = evt
+			}
+		}
+	}
+
+	return exchanges
+}
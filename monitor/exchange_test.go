@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func TestRecorderCorrelate(t *testing.T) {
+	r := NewRecorder()
+	cm := r.CommandMonitor()
+	ctx := context.Background()
+
+	failure := errors.New("boom")
+
+	cm.Started(ctx, &event.CommandStartedEvent{CommandName: "insert", RequestID: 1, ConnectionID: "a[-1]"})
+	cm.Started(ctx, &event.CommandStartedEvent{CommandName: "find", RequestID: 2, ConnectionID: "a[-2]"})
+	cm.Started(ctx, &event.CommandStartedEvent{CommandName: "getMore", RequestID: 3, ConnectionID: "a[-2]"})
+
+	cm.Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 2, ConnectionID: "a[-2]"},
+		Failure:              failure,
+	})
+	cm.Succeeded(ctx, &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", RequestID: 1, ConnectionID: "a[-1]"},
+	})
+
+	exchanges := r.Correlate()
+	require.Len(t, exchanges, 3)
+
+	assert.Equal(t, "insert", exchanges[0].Started.CommandName)
+	assert.NotNil(t, exchanges[0].Succeeded)
+	assert.Nil(t, exchanges[0].Failed)
+
+	assert.Equal(t, "find", exchanges[1].Started.CommandName)
+	require.NotNil(t, exchanges[1].Failed)
+	assert.ErrorIs(t, exchanges[1].Failed.Failure, failure)
+
+	// Still in flight.
+	assert.Equal(t, "getMore", exchanges[2].Started.CommandName)
+	assert.Nil(t, exchanges[2].Succeeded)
+	assert.Nil(t, exchanges[2].Failed)
+}
@@ -0,0 +1,203 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// goldenIgnored are the fields that differ between runs of the same
+// operations, so they are never compared: sessions, cluster times, and
+// cursor IDs.
+var goldenIgnored = []string{
+	"command.lsid",
+	"command.$clusterTime",
+	"command.getMore",
+	"reply.$clusterTime",
+	"reply.operationTime",
+	"reply.cursor.id",
+}
+
+// SaveGolden writes r's recorded command events to a golden file at path, as
+// indented JSON, for AssertMatchesGolden to compare later runs against. Only
+// command events are saved: pool and server events depend on timing. Each
+// event keeps its type, command name, database, and command, reply, or
+// failure, with the commands in relaxed extended JSON.
+func (r *Recorder) SaveGolden(path string) error {
+	events, err := r.goldenEvents()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal golden events: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create golden file directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write golden file: %w", err)
+	}
+
+	return nil
+}
+
+// AssertMatchesGolden fails the test unless r's recorded command events match
+// the golden file at path, as written by SaveGolden. ignoreFields are dotted
+// paths into each event left out of the comparison, e.g. "command.documents"
+// or "reply.cursor.firstBatch.*._id": a number indexes an array, and "*"
+// matches every field or element. Session IDs, cluster times, and cursor IDs
+// are always ignored.
+func (r *Recorder) AssertMatchesGolden(t *testing.T, path string, ignoreFields ...string) {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read golden file")
+
+	var want []any
+	require.NoError(t, json.Unmarshal(b, &want), "failed to decode golden file %s", path)
+
+	events, err := r.goldenEvents()
+	require.NoError(t, err)
+
+	// Round trip the recorded events so both sides have the same JSON types.
+	b, err = json.Marshal(events)
+	require.NoError(t, err)
+
+	var got []any
+	require.NoError(t, json.Unmarshal(b, &got))
+
+	for _, field := range ignoreFields {
+		segs := strings.Split(field, ".")
+
+		for _, v := range want {
+			deleteField(v, segs)
+		}
+
+		for _, v := range got {
+			deleteField(v, segs)
+		}
+	}
+
+	require.Equal(t, want, got, "recorded command events don't match golden file %s", path)
+}
+
+// goldenEvents returns the recorded command events in their golden form,
+// without the always ignored fields.
+func (r *Recorder) goldenEvents() ([]map[string]any, error) {
+	events := []map[string]any{}
+
+	for _, e := range r.Events() {
+		var (
+			golden map[string]any
+			err    error
+		)
+
+		switch evt := e.Event.(type) {
+		case *event.CommandStartedEvent:
+			golden = map[string]any{
+				"type":         "commandStarted",
+				"commandName":  evt.CommandName,
+				"databaseName": evt.DatabaseName,
+			}
+			golden["command"], err = goldenDocument(evt.Command)
+		case *event.CommandSucceededEvent:
+			golden = map[string]any{
+				"type":         "commandSucceeded",
+				"commandName":  evt.CommandName,
+				"databaseName": evt.DatabaseName,
+			}
+			golden["reply"], err = goldenDocument(evt.Reply)
+		case *event.CommandFailedEvent:
+			golden = map[string]any{
+				"type":         "commandFailed",
+				"commandName":  evt.CommandName,
+				"databaseName": evt.DatabaseName,
+				"failure":      fmt.Sprint(evt.Failure),
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("convert %s event to golden form: %w", golden["commandName"], err)
+		}
+
+		for _, field := range goldenIgnored {
+			deleteField(golden, strings.Split(field, "."))
+		}
+
+		events = append(events, golden)
+	}
+
+	return events, nil
+}
+
+// goldenDocument converts doc to relaxed extended JSON values. Redacted
+// (empty) documents become empty objects.
+func goldenDocument(doc bson.Raw) (any, error) {
+	if len(doc) == 0 {
+		return map[string]any{}, nil
+	}
+
+	b, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// deleteField removes the field at the path segs from v, a decoded JSON
+// value. Missing fields are ignored.
+func deleteField(v any, segs []string) {
+	if len(segs) == 0 {
+		return
+	}
+
+	seg, rest := segs[0], segs[1:]
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if seg != "*" && seg != key {
+				continue
+			}
+
+			if len(rest) == 0 {
+				delete(v, key)
+			} else {
+				deleteField(child, rest)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if seg != "*" && seg != strconv.Itoa(i) {
+				continue
+			}
+
+			// Removing an element would shift the others, so null it.
+			if len(rest) == 0 {
+				v[i] = nil
+			} else {
+				deleteField(child, rest)
+			}
+		}
+	}
+}
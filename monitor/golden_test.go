@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func recordInsert(t *testing.T, r *Recorder, id bson.ObjectID) {
+	t.Helper()
+
+	cmd, err := bson.Marshal(bson.D{
+		{Key: "insert", Value: "coll"},
+		{Key: "documents", Value: bson.A{bson.D{{Key: "_id", Value: id}, {Key: "x", Value: 1}}}},
+		{Key: "lsid", Value: bson.D{{Key: "id", Value: bson.NewObjectID()}}},
+	})
+	require.NoError(t, err)
+
+	reply, err := bson.Marshal(bson.D{{Key: "n", Value: 1}, {Key: "ok", Value: 1.0}})
+	require.NoError(t, err)
+
+	r.CommandMonitor().Started(context.Background(), &event.CommandStartedEvent{
+		CommandName: "insert", DatabaseName: "db", RequestID: 1, Command: cmd,
+	})
+	r.CommandMonitor().Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", DatabaseName: "db", RequestID: 1},
+		Reply:                reply,
+	})
+
+	// Pool events aren't part of the golden timeline.
+	r.PoolMonitor().Event(&event.PoolEvent{Type: event.ConnectionCheckedOut, Address: "localhost:27017"})
+}
+
+func TestRecorderGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "insert.json")
+
+	r := NewRecorder()
+	recordInsert(t, r, bson.NewObjectID())
+	require.NoError(t, r.SaveGolden(path))
+
+	// The session ID differs but is always ignored; the _id must be
+	// ignored explicitly.
+	r.Reset()
+	recordInsert(t, r, bson.NewObjectID())
+	r.AssertMatchesGolden(t, path, "command.documents.*._id")
+}
+
+func TestDeleteField(t *testing.T) {
+	v := map[string]any{
+		"a": map[string]any{"b": 1.0, "c": 2.0},
+		"d": []any{map[string]any{"e": 1.0, "f": 2.0}, 3.0},
+	}
+
+	deleteField(v, []string{"a", "b"})
+	deleteField(v, []string{"d", "*", "e"})
+	deleteField(v, []string{"d", "1"})
+	deleteField(v, []string{"missing", "x"})
+
+	assert.Equal(t, map[string]any{
+		"a": map[string]any{"c": 2.0},
+		"d": []any{map[string]any{"f": 2.0}, nil},
+	}, v)
+}
@@ -0,0 +1,255 @@
+package monitor
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// typeAliases are the $$type names of BSON types, as in the $type query
+// operator.
+var typeAliases = map[string]bson.Type{
+	"double":     bson.TypeDouble,
+	"string":     bson.TypeString,
+	"object":     bson.TypeEmbeddedDocument,
+	"array":      bson.TypeArray,
+	"binData":    bson.TypeBinary,
+	"undefined":  bson.TypeUndefined,
+	"objectId":   bson.TypeObjectID,
+	"bool":       bson.TypeBoolean,
+	"date":       bson.TypeDateTime,
+	"null":       bson.TypeNull,
+	"regex":      bson.TypeRegex,
+	"dbPointer":  bson.TypeDBPointer,
+	"javascript": bson.TypeJavaScript,
+	"symbol":     bson.TypeSymbol,
+	"int":        bson.TypeInt32,
+	"timestamp":  bson.TypeTimestamp,
+	"long":       bson.TypeInt64,
+	"decimal":    bson.TypeDecimal128,
+	"minKey":     bson.TypeMinKey,
+	"maxKey":     bson.TypeMaxKey,
+}
+
+// AssertCommandStartedMatching fails the test unless a started cmd matches
+// expected, any document bson.Marshal accepts:
+//
+//	rec.AssertCommandStartedMatching(t, "insert", bson.D{
+//		{"writeConcern", bson.D{{"w", "majority"}}},
+//		{"lsid", bson.D{{"$$exists", true}}},
+//		{"txnNumber", bson.D{{"$$type", bson.A{"int", "long"}}}},
+//	})
+//
+// The matching follows the unified test format: the command may have fields
+// expected doesn't, but embedded documents and arrays must match exactly,
+// and numbers match by value across int, long, and double. An expected value
+// {$$exists: bool} matches if the field is (or isn't) present, and
+// {$$type: alias} or {$$type: [aliases...]} matches a value of that BSON type
+// ("string", "int", "long", "objectId", ...).
+func (r *Recorder) AssertCommandStartedMatching(t *testing.T, cmd string, expected any) {
+	t.Helper()
+
+	want, err := bson.Marshal(expected)
+	require.NoError(t, err, "failed to marshal expected command")
+
+	var mismatches []string
+	for _, evt := range r.CommandStartedEvents() {
+		if evt.CommandName != cmd {
+			continue
+		}
+
+		err := matchDocument("", want, evt.Command, true)
+		if err == nil {
+			return
+		}
+
+		mismatches = append(mismatches, fmt.Sprintf("request %d: %v", evt.RequestID, err))
+	}
+
+	if len(mismatches) == 0 {
+		require.Failf(t, "command not started", "expected a %q command matching %v", cmd, bson.Raw(want))
+	}
+
+	require.Failf(t, "no command matched", "expected a %q command matching %v:\n%s", cmd, bson.Raw(want), strings.Join(mismatches, "\n"))
+}
+
+// matchDocument returns an error describing the first difference between
+// the expected and actual documents at path. If root is set, actual may have
+// extra fields.
+func matchDocument(path string, expected, actual bson.Raw, root bool) error {
+	elems, err := expected.Elements()
+	if err != nil {
+		return fmt.Errorf("%s: invalid expected document: %w", pathOrRoot(path), err)
+	}
+
+	for _, elem := range elems {
+		key := elem.Key()
+		field := joinPath(path, key)
+
+		if exists, ok := operand(elem.Value(), "$$exists"); ok {
+			_, err := actual.LookupErr(key)
+			if want := exists.Boolean(); want != (err == nil) {
+				return fmt.Errorf("%s: expected exists to be %t", field, want)
+			}
+
+			continue
+		}
+
+		got, err := actual.LookupErr(key)
+		if err != nil {
+			return fmt.Errorf("%s: missing", field)
+		}
+
+		if err := matchValue(field, elem.Value(), got); err != nil {
+			return err
+		}
+	}
+
+	if root {
+		return nil
+	}
+
+	got, err := actual.Elements()
+	if err != nil {
+		return fmt.Errorf("%s: invalid document: %w", pathOrRoot(path), err)
+	}
+
+	for _, elem := range got {
+		if _, err := expected.LookupErr(elem.Key()); err != nil {
+			return fmt.Errorf("%s: unexpected field", joinPath(path, elem.Key()))
+		}
+	}
+
+	return nil
+}
+
+// matchValue returns an error describing the first difference between the
+// expected and actual values at path.
+func matchValue(path string, expected, actual bson.RawValue) error {
+	if aliases, ok := operand(expected, "$$type"); ok {
+		return matchType(path, aliases, actual)
+	}
+
+	switch {
+	case expected.Type == bson.TypeEmbeddedDocument && actual.Type == bson.TypeEmbeddedDocument:
+		return matchDocument(path, expected.Document(), actual.Document(), false)
+	case expected.Type == bson.TypeArray && actual.Type == bson.TypeArray:
+		want, _ := expected.Array().Values()
+		got, _ := actual.Array().Values()
+
+		if len(want) != len(got) {
+			return fmt.Errorf("%s: expected %d elements, got %d", path, len(want), len(got))
+		}
+
+		for i := range want {
+			if err := matchValue(fmt.Sprintf("%s.%d", path, i), want[i], got[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case isNumber(expected) && isNumber(actual):
+		if !numbersEqual(expected, actual) {
+			return fmt.Errorf("%s: expected %s, got %s", path, numberString(expected), numberString(actual))
+		}
+
+		return nil
+	}
+
+	if !expected.Equal(actual) {
+		return fmt.Errorf("%s: expected %v, got %v", path, expected, actual)
+	}
+
+	return nil
+}
+
+// matchType returns an error unless actual has one of the BSON types named
+// by aliases, a string or an array of strings.
+func matchType(path string, aliases, actual bson.RawValue) error {
+	var names []string
+	if aliases.Type == bson.TypeArray {
+		values, _ := aliases.Array().Values()
+		for _, v := range values {
+			names = append(names, v.StringValue())
+		}
+	} else {
+		names = append(names, aliases.StringValue())
+	}
+
+	for _, name := range names {
+		typ, ok := typeAliases[name]
+		if !ok {
+			return fmt.Errorf("%s: unknown $$type %q", path, name)
+		}
+
+		if actual.Type == typ {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: expected type %s, got %v", path, strings.Join(names, " or "), actual.Type)
+}
+
+// operand returns the operand of v if v is a single field document {op: x}.
+func operand(v bson.RawValue, op string) (bson.RawValue, bool) {
+	doc, ok := v.DocumentOK()
+	if !ok {
+		return bson.RawValue{}, false
+	}
+
+	elems, err := doc.Elements()
+	if err != nil || len(elems) != 1 || elems[0].Key() != op {
+		return bson.RawValue{}, false
+	}
+
+	return elems[0].Value(), true
+}
+
+func isNumber(v bson.RawValue) bool {
+	return slices.Contains([]bson.Type{bson.TypeInt32, bson.TypeInt64, bson.TypeDouble}, v.Type)
+}
+
+func numbersEqual(a, b bson.RawValue) bool {
+	if a.Type != bson.TypeDouble && b.Type != bson.TypeDouble {
+		return a.AsInt64() == b.AsInt64()
+	}
+
+	return toFloat(a) == toFloat(b)
+}
+
+func toFloat(v bson.RawValue) float64 {
+	if v.Type == bson.TypeDouble {
+		return v.Double()
+	}
+
+	return float64(v.AsInt64())
+}
+
+func numberString(v bson.RawValue) string {
+	if v.Type == bson.TypeDouble {
+		return strconv.FormatFloat(v.Double(), 'g', -1, 64)
+	}
+
+	return strconv.FormatInt(v.AsInt64(), 10)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+
+	return path
+}
@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func mustMarshal(t *testing.T, doc any) bson.Raw {
+	t.Helper()
+
+	b, err := bson.Marshal(doc)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestMatchDocument(t *testing.T) {
+	actual := mustMarshal(t, bson.D{
+		{Key: "insert", Value: "coll"},
+		{Key: "writeConcern", Value: bson.D{{Key: "w", Value: "majority"}, {Key: "wtimeout", Value: int64(100)}}},
+		{Key: "txnNumber", Value: int64(1)},
+		{Key: "documents", Value: bson.A{bson.D{{Key: "x", Value: int32(1)}}}},
+	})
+
+	for _, tc := range []struct {
+		name     string
+		expected bson.D
+		err      string
+	}{
+		{
+			name:     "extra root fields",
+			expected: bson.D{{Key: "insert", Value: "coll"}},
+		},
+		{
+			name:     "numbers by value",
+			expected: bson.D{{Key: "txnNumber", Value: 1.0}, {Key: "documents", Value: bson.A{bson.D{{Key: "x", Value: int64(1)}}}}},
+		},
+		{
+			name:     "exists",
+			expected: bson.D{{Key: "txnNumber", Value: bson.D{{Key: "$$exists", Value: true}}}, {Key: "lsid", Value: bson.D{{Key: "$$exists", Value: false}}}},
+		},
+		{
+			name:     "type",
+			expected: bson.D{{Key: "txnNumber", Value: bson.D{{Key: "$$type", Value: bson.A{"int", "long"}}}}},
+		},
+		{
+			name:     "embedded document exactly",
+			expected: bson.D{{Key: "writeConcern", Value: bson.D{{Key: "w", Value: "majority"}}}},
+			err:      "writeConcern.wtimeout: unexpected field",
+		},
+		{
+			name:     "missing",
+			expected: bson.D{{Key: "lsid", Value: bson.D{}}},
+			err:      "lsid: missing",
+		},
+		{
+			name:     "wrong type",
+			expected: bson.D{{Key: "insert", Value: bson.D{{Key: "$$type", Value: "int"}}}},
+			err:      "insert: expected type int, got string",
+		},
+		{
+			name:     "array element",
+			expected: bson.D{{Key: "documents", Value: bson.A{bson.D{{Key: "x", Value: 2}}}}},
+			err:      "documents.0.x: expected 2, got 1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := matchDocument("", mustMarshal(t, tc.expected), actual, true)
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestRecorderAssertCommandStartedMatching(t *testing.T) {
+	r := NewRecorder()
+
+	r.CommandMonitor().Started(context.Background(), &event.CommandStartedEvent{
+		CommandName: "insert",
+		RequestID:   1,
+		Command:     mustMarshal(t, bson.D{{Key: "insert", Value: "coll"}, {Key: "ordered", Value: true}}),
+	})
+
+	r.AssertCommandStartedMatching(t, "insert", bson.D{{Key: "ordered", Value: true}})
+}
@@ -1,9 +1,8 @@
 package monitor
 
 import (
-	"context"
-	"sync"
 	"testing"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/event"
 )
@@ -17,206 +16,76 @@ const (
 	EventConnectionCheckedIn
 	EventConnectionClosed
 	EventPoolCleared
+	EventTopologyDescriptionChanged
+	EventCommandSucceeded
+	EventServerHeartbeatStarted
+	EventServerHeartbeatSucceeded
+	EventServerHeartbeatFailed
+	EventServerOpening
+	EventServerClosed
+	EventConnectionCreated
+	EventConnectionReady
+	EventConnectionCheckOutStarted
+	EventConnectionCheckOutFailed
+	EventServerDescriptionChanged
 )
 
+// legacyEvents are the event types the deprecated Monitor records.
+var legacyEvents = map[EventType]bool{
+	EventCommandStarted:       true,
+	EventCommandFailed:        true,
+	EventConnectionCheckedOut: true,
+	EventConnectionCheckedIn:  true,
+	EventConnectionClosed:     true,
+	EventPoolCleared:          true,
+}
+
+// withLegacyFilter makes a Recorder keep the deprecated Monitor's contract:
+// only the commands named with WithCommands, and only legacyEvents.
+func withLegacyFilter() RecorderOption {
+	return func(o *recorderOptions) {
+		o.legacy = true
+	}
+}
+
 type RecordedEvent struct {
 	Type  EventType
 	Event any
+	// Time is when the event was recorded.
+	Time time.Time
 }
 
+// Monitor records command and pool events for a test.
+//
+// Deprecated: Use Recorder, which also records server events and can be
+// installed on a client with one call.
 type Monitor struct {
 	CommandMonitor *event.CommandMonitor
 	PoolMonitor    *event.PoolMonitor
 
-	allEvents []RecordedEvent
-	eventMu   sync.Mutex
+	*Recorder
 }
 
+// New creates a Monitor recording the started and failed events of the named
+// commands (none if no names are given) and the connection checked out,
+// checked in, closed, and pool cleared events, logging them to t if shouldLog
+// is set. Succeeded commands are logged but not recorded.
+//
+// Deprecated: Use NewRecorder with WithCommands and WithLogging, which
+// records every event type and, without WithCommands, every command.
 func New(t *testing.T, shouldLog bool, cmds ...string) *Monitor {
 	t.Helper()
 
-	monitor := &Monitor{}
-	monitor.Reset()
-
-	monitor.CommandMonitor = &event.CommandMonitor{
-		Started: func(ctx context.Context, cse *event.CommandStartedEvent) {
-			for _, cmd := range cmds {
-				if cse.CommandName == cmd {
-					if shouldLog {
-						t.Logf("command started: %+v\n", cse)
-					}
-
-					monitor.eventMu.Lock()
-					monitor.allEvents = append(monitor.allEvents, RecordedEvent{Type: EventCommandStarted, Event: cse})
-					monitor.eventMu.Unlock()
-				}
-			}
-		},
-		Succeeded: func(ctx context.Context, cse *event.CommandSucceededEvent) {
-			for _, cmd := range cmds {
-				if cse.CommandName == cmd {
-					if shouldLog {
-						t.Logf("command Succeeded: %+v\n", cse)
-					}
-				}
-			}
-		},
-		Failed: func(ctx context.Context, cse *event.CommandFailedEvent) {
-			for _, cmd := range cmds {
-				if cse.CommandName == cmd {
-					if shouldLog {
-						t.Logf("command failed: %+v\n", cse)
-					}
-
-					monitor.eventMu.Lock()
-					monitor.allEvents = append(monitor.allEvents, RecordedEvent{Type: EventCommandFailed, Event: cse})
-					monitor.eventMu.Unlock()
-				}
-			}
-		},
-	}
-
-	monitor.PoolMonitor = &event.PoolMonitor{
-		Event: func(pe *event.PoolEvent) {
-			switch pe.Type {
-			case event.ConnectionCheckedIn:
-				if shouldLog {
-					t.Logf("connection checked in: %+v\n", pe)
-				}
-
-				monitor.eventMu.Lock()
-				monitor.allEvents = append(monitor.allEvents, RecordedEvent{Type: EventConnectionCheckedIn, Event: pe})
-				monitor.eventMu.Unlock()
-			case event.ConnectionCheckedOut:
-				if shouldLog {
-					t.Logf("connection checked out: %+v\n", pe)
-				}
-
-				monitor.eventMu.Lock()
-				monitor.allEvents = append(monitor.allEvents, RecordedEvent{Type: EventConnectionCheckedOut, Event: pe})
-				monitor.eventMu.Unlock()
-			case event.ConnectionClosed:
-				if shouldLog {
-					t.Logf("connection closed: %+v\n", pe)
-				}
-
-				monitor.eventMu.Lock()
-				monitor.allEvents = append(monitor.allEvents, RecordedEvent{Type: EventConnectionClosed, Event: pe})
-				monitor.eventMu.Unlock()
-			case event.ConnectionPoolCleared:
-				if shouldLog {
-					t.Logf("pool cleared (interruptInUseConnections=%t): %+v\n", pe.Interruption, pe)
-				}
-
-				monitor.eventMu.Lock()
-				monitor.allEvents = append(monitor.allEvents, RecordedEvent{Type: EventPoolCleared, Event: pe})
-				monitor.eventMu.Unlock()
-			}
-		},
+	opts := []RecorderOption{WithCommands(cmds...), withLegacyFilter()}
+	if shouldLog {
+		opts = append(opts, WithLogging(t))
 	}
 
-	return monitor
-}
-
-func (m *Monitor) Reset() {
-	m.allEvents = nil
-	m.eventMu = sync.Mutex{}
-}
-
-// Events returns a copy of all recorded events in order.
-func (m *Monitor) Events() []RecordedEvent {
-	m.eventMu.Lock()
-	defer m.eventMu.Unlock()
+	rec := NewRecorder(opts...)
 
-	return append([]RecordedEvent(nil), m.allEvents...)
-}
-
-// CommandStartedEvents returns all command started events in order.
-func (m *Monitor) CommandStartedEvents() []*event.CommandStartedEvent {
-	m.eventMu.Lock()
-	defer m.eventMu.Unlock()
-
-	var events []*event.CommandStartedEvent
-	for _, e := range m.allEvents {
-		if e.Type == EventCommandStarted {
-			events = append(events, e.Event.(*event.CommandStartedEvent))
-		}
+	return &Monitor{
+		CommandMonitor: rec.CommandMonitor(),
+		PoolMonitor:    rec.PoolMonitor(),
+		Recorder:       rec,
 	}
-
-	return events
-}
-
-// CommandFailedEvents returns all command failed events in order.
-func (m *Monitor) CommandFailedEvents() []*event.CommandFailedEvent {
-	m.eventMu.Lock()
-	defer m.eventMu.Unlock()
-
-	var events []*event.CommandFailedEvent
-	for _, e := range m.allEvents {
-		if e.Type == EventCommandFailed {
-			events = append(events, e.Event.(*event.CommandFailedEvent))
-		}
-	}
-
-	return events
-}
-
-// ConnectionCheckedOutEvents returns all connection checked out events in order.
-func (m *Monitor) ConnectionCheckedOutEvents() []*event.PoolEvent {
-	m.eventMu.Lock()
-	defer m.eventMu.Unlock()
-
-	var events []*event.PoolEvent
-	for _, e := range m.allEvents {
-		if e.Type == EventConnectionCheckedOut {
-			events = append(events, e.Event.(*event.PoolEvent))
-		}
-	}
-
-	return events
-}
-
-// ConnectionCheckedInEvents returns all connection checked in events in order.
-func (m *Monitor) ConnectionCheckedInEvents() []*event.PoolEvent {
-	m.eventMu.Lock()
-	defer m.eventMu.Unlock()
-
-	var events []*event.PoolEvent
-	for _, e := range m.allEvents {
-		if e.Type == EventConnectionCheckedIn {
-			events = append(events, e.Event.(*event.PoolEvent))
-		}
-	}
-
-	return events
-}
-
-// PoolClearedEvents returns all pool cleared events in order.
-func (m *Monitor) PoolClearedEvents() []*event.PoolEvent {
-	m.eventMu.Lock()
-	defer m.eventMu.Unlock()
-
-	var events []*event.PoolEvent
-	for _, e := range m.allEvents {
-		if e.Type == EventPoolCleared {
-			events = append(events, e.Event.(*event.PoolEvent))
-		}
-	}
-
-	return events
-}
-
-// ConnectionClosedEvents returns all connection closed events in order.
-func (m *Monitor) ConnectionClosedEvents() []*event.PoolEvent {
-	m.eventMu.Lock()
-	defer m.eventMu.Unlock()
-
-	var events []*event.PoolEvent
-	for _, e := range m.allEvents {
-		if e.Type == EventConnectionClosed {
-			events = append(events, e.Event.(*event.PoolEvent))
-		}
-	}
-
-	return events
 }
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func TestNewKeepsMonitorContract(t *testing.T) {
+	feed := func(m *Monitor) {
+		for i, name := range []string{"find", "insert"} {
+			id := int64(i)
+			finished := event.CommandFinishedEvent{CommandName: name, RequestID: id}
+
+			m.CommandMonitor.Started(context.Background(), &event.CommandStartedEvent{CommandName: name, RequestID: id})
+			m.CommandMonitor.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+		}
+
+		m.CommandMonitor.Started(context.Background(), &event.CommandStartedEvent{CommandName: "find", RequestID: 2})
+		m.CommandMonitor.Failed(context.Background(), &event.CommandFailedEvent{
+			CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 2},
+		})
+
+		for _, typ := range []string{
+			event.ConnectionCreated, event.ConnectionReady, event.ConnectionCheckOutStarted,
+			event.ConnectionCheckedOut, event.ConnectionCheckedIn, event.ConnectionClosed,
+			event.ConnectionPoolCleared, event.ConnectionCheckOutFailed,
+		} {
+			m.PoolMonitor.Event(&event.PoolEvent{Type: typ})
+		}
+
+		m.ServerMonitor().TopologyDescriptionChanged(&event.TopologyDescriptionChangedEvent{})
+	}
+
+	t.Run("named commands", func(t *testing.T) {
+		m := New(t, false, "find")
+		feed(m)
+
+		var types []EventType
+		for _, e := range m.Events() {
+			types = append(types, e.Type)
+		}
+
+		assert.Equal(t, []EventType{
+			EventCommandStarted,
+			EventCommandStarted,
+			EventCommandFailed,
+			EventConnectionCheckedOut,
+			EventConnectionCheckedIn,
+			EventConnectionClosed,
+			EventPoolCleared,
+		}, types)
+	})
+
+	t.Run("no commands", func(t *testing.T) {
+		m := New(t, false)
+		feed(m)
+
+		assert.Empty(t, m.CommandStartedEvents())
+		assert.Empty(t, m.CommandFailedEvents())
+		assert.Len(t, m.Events(), 4)
+	})
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/prestonvasquez/go-playground/mongoevent"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans the Recorder emits.
+const tracerName = "github.com/prestonvasquez/go-playground/monitor"
+
+// WithTracerProvider emits each recorded command, once it finishes, as a
+// client span from a tracer of tp, with the command name, database, server
+// address, and error. Configure tp with the exporter to prototype against,
+// e.g. an SDK provider with a stdout or OTLP exporter. Spans are children of
+// the span in the operation's context, if any.
+func WithTracerProvider(tp trace.TracerProvider) RecorderOption {
+	return func(o *recorderOptions) {
+		o.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// emitSpan emits the span of a finished command if r has a tracer. failure is
+// the error of a failed command, or nil.
+func (r *Recorder) emitSpan(ctx context.Context, evt event.CommandFinishedEvent, failure error) {
+	if r.cfg.tracer == nil {
+		return
+	}
+
+	end := time.Now()
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system.name", "mongodb"),
+		attribute.String("db.operation.name", evt.CommandName),
+		attribute.String("db.namespace", evt.DatabaseName),
+		attribute.Int64("db.mongodb.request_id", evt.RequestID),
+	}
+
+	addr := mongoevent.ConnectionAddress(evt.ConnectionID)
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		attrs = append(attrs, attribute.String("server.address", host))
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, attribute.Int("server.port", p))
+		}
+	} else if addr != "" {
+		attrs = append(attrs, attribute.String("server.address", addr))
+	}
+
+	_, span := r.cfg.tracer.Start(ctx, evt.CommandName+" "+evt.DatabaseName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(end.Add(-evt.Duration)),
+		trace.WithAttributes(attrs...),
+	)
+
+	if failure != nil {
+		span.RecordError(failure)
+		span.SetStatus(codes.Error, failure.Error())
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
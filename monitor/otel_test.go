@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// fakeSpan records what is set on it.
+type fakeSpan struct {
+	noop.Span
+
+	name       string
+	start, end time.Time
+	attrs      map[attribute.Key]attribute.Value
+	status     codes.Code
+	err        error
+}
+
+func (s *fakeSpan) RecordError(err error, _ ...trace.EventOption) { s.err = err }
+
+func (s *fakeSpan) SetStatus(code codes.Code, _ string) { s.status = code }
+
+func (s *fakeSpan) End(opts ...trace.SpanEndOption) {
+	cfg := trace.NewSpanEndConfig(opts...)
+	s.end = cfg.Timestamp()
+}
+
+type fakeTracerProvider struct {
+	embedded.TracerProvider
+
+	tracer fakeTracer
+}
+
+func (tp *fakeTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer { return &tp.tracer }
+
+// fakeTracer records the spans it starts.
+type fakeTracer struct {
+	embedded.Tracer
+
+	spans []*fakeSpan
+}
+
+func (tr *fakeTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+
+	span := &fakeSpan{name: name, start: cfg.Timestamp(), attrs: map[attribute.Key]attribute.Value{}}
+	for _, kv := range cfg.Attributes() {
+		span.attrs[kv.Key] = kv.Value
+	}
+
+	tr.spans = append(tr.spans, span)
+
+	return ctx, span
+}
+
+func TestRecorderTracerProvider(t *testing.T) {
+	tp := &fakeTracerProvider{}
+	r := NewRecorder(WithTracerProvider(tp))
+
+	finished := event.CommandFinishedEvent{
+		CommandName:  "insert",
+		DatabaseName: "db",
+		RequestID:    1,
+		ConnectionID: "localhost:27017[-4]",
+		Duration:     5 * time.Millisecond,
+	}
+
+	r.CommandMonitor().Started(context.Background(), &event.CommandStartedEvent{CommandName: "insert", RequestID: 1})
+	r.CommandMonitor().Failed(context.Background(), &event.CommandFailedEvent{
+		CommandFinishedEvent: finished,
+		Failure:              errors.New("boom"),
+	})
+
+	// Outcomes that aren't recorded aren't exported either.
+	succeedFind(r, 2)
+
+	require.Len(t, tp.tracer.spans, 1)
+
+	span := tp.tracer.spans[0]
+	assert.Equal(t, "insert db", span.name)
+	assert.Equal(t, 5*time.Millisecond, span.end.Sub(span.start))
+	assert.Equal(t, "insert", span.attrs["db.operation.name"].AsString())
+	assert.Equal(t, "localhost", span.attrs["server.address"].AsString())
+	assert.EqualValues(t, 27017, span.attrs["server.port"].AsInt64())
+	assert.Equal(t, codes.Error, span.status)
+	assert.EqualError(t, span.err, "boom")
+}
@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// Collector returns a prometheus.Collector exposing r's recorded commands and
+// the connection pool state, so a running load test can be scraped:
+//
+//   - commands_total: the finished commands, by command and outcome
+//     ("succeeded" or "failed").
+//   - command_duration_seconds: a histogram of the command durations, by
+//     command.
+//   - connections_checked_out: the connections currently checked out, by
+//     server address.
+//
+// Commands are counted from the recorded events, so the counts restart on
+// Reset (which Prometheus treats as a counter reset) and, with WithMaxEvents,
+// only cover the retained events.
+func (r *Recorder) Collector() prometheus.Collector {
+	return &collector{
+		r: r,
+		commands: prometheus.NewDesc("commands_total",
+			"Number of finished commands.", []string{"command", "outcome"}, nil),
+		duration: prometheus.NewDesc("command_duration_seconds",
+			"Duration of finished commands.", []string{"command"}, nil),
+		checkedOut: prometheus.NewDesc("connections_checked_out",
+			"Number of connections checked out of the pool.", []string{"address"}, nil),
+	}
+}
+
+type collector struct {
+	r *Recorder
+
+	commands   *prometheus.Desc
+	duration   *prometheus.Desc
+	checkedOut *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*collector)(nil)
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.commands
+	ch <- c.duration
+	ch <- c.checkedOut
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	type outcome struct {
+		cmd, outcome string
+	}
+
+	counts := map[outcome]int{}
+	durations := map[string][]time.Duration{}
+
+	c.r.mu.Lock()
+	for _, e := range c.r.ordered() {
+		var finished event.CommandFinishedEvent
+
+		switch evt := e.Event.(type) {
+		case *event.CommandSucceededEvent:
+			finished = evt.CommandFinishedEvent
+			counts[outcome{finished.CommandName, "succeeded"}]++
+		case *event.CommandFailedEvent:
+			finished = evt.CommandFinishedEvent
+			counts[outcome{finished.CommandName, "failed"}]++
+		default:
+			continue
+		}
+
+		durations[finished.CommandName] = append(durations[finished.CommandName], finished.Duration)
+	}
+	c.r.mu.Unlock()
+
+	for o, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.commands, prometheus.CounterValue, float64(n), o.cmd, o.outcome)
+	}
+
+	for cmd, ds := range durations {
+		var sum float64
+
+		buckets := make(map[float64]uint64, len(prometheus.DefBuckets))
+		for _, d := range ds {
+			secs := d.Seconds()
+			sum += secs
+
+			for _, upper := range prometheus.DefBuckets {
+				if secs <= upper {
+					buckets[upper]++
+				}
+			}
+		}
+
+		ch <- prometheus.MustNewConstHistogram(c.duration, uint64(len(ds)), sum, buckets, cmd)
+	}
+
+	for _, addr := range c.r.pool.Addresses() {
+		pc := c.r.pool.Counts(addr)
+		ch <- prometheus.MustNewConstMetric(c.checkedOut, prometheus.GaugeValue, float64(pc.CheckedOut-pc.CheckedIn), addr)
+	}
+}
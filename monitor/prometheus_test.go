@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func TestRecorderCollector(t *testing.T) {
+	r := NewRecorder()
+
+	for id := range int64(3) {
+		startFind(r, id)
+	}
+
+	r.CommandMonitor().Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 0, Duration: 2 * time.Millisecond},
+	})
+	r.CommandMonitor().Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1, Duration: 2 * time.Second},
+	})
+	r.CommandMonitor().Failed(context.Background(), &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 2},
+	})
+
+	for _, typ := range []string{event.ConnectionCheckedOut, event.ConnectionCheckedOut, event.ConnectionCheckedIn} {
+		r.PoolMonitor().Event(&event.PoolEvent{Type: typ, Address: "localhost:27017"})
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(r.Collector()))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	got := map[string]float64{}
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			key := mf.GetName()
+			for _, l := range m.GetLabel() {
+				key += " " + l.GetValue()
+			}
+
+			switch {
+			case m.GetCounter() != nil:
+				got[key] = m.GetCounter().GetValue()
+			case m.GetGauge() != nil:
+				got[key] = m.GetGauge().GetValue()
+			case m.GetHistogram() != nil:
+				got[key] = float64(m.GetHistogram().GetSampleCount())
+				got[key+" sum"] = m.GetHistogram().GetSampleSum()
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]float64{
+		"commands_total find succeeded":           2,
+		"commands_total find failed":              1,
+		"command_duration_seconds find":           3,
+		"command_duration_seconds find sum":       2.002,
+		"connections_checked_out localhost:27017": 1,
+	}, got)
+}
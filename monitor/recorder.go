@@ -0,0 +1,654 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prestonvasquez/go-playground/mongoevent"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Recorder records the command, connection pool, and server monitoring
+// events of a client in the order they arrive. Sensitive commands, such as
+// saslStart and hellos carrying speculative authentication, are recorded with
+// their command and reply emptied unless WithUnredacted is set. Build one and
+// install all of its monitors with ClientOptions:
+//
+//	rec := monitor.NewRecorder(monitor.WithCommands("find", "getMore"))
+//	client, err := mongo.Connect(rec.ClientOptions().ApplyURI(uri))
+type Recorder struct {
+	cfg recorderOptions
+
+	// pool and server keep the derived state (ready connections, latest
+	// topology, round trip times) the event timeline doesn't.
+	pool   *mongoevent.PoolMonitor
+	server *mongoevent.ServerMonitor
+
+	commandMonitor *event.CommandMonitor
+	poolMonitor    *event.PoolMonitor
+	serverMonitor  *event.ServerMonitor
+
+	mu     sync.Mutex
+	events []RecordedEvent
+	// With WithMaxEvents, events is a ring buffer once full: head is the
+	// index of the oldest event, and dropped counts the events overwritten
+	// in this generation.
+	head    int
+	dropped int
+	// recorded is closed, and replaced, whenever an event is recorded.
+	recorded chan struct{}
+	// chained are the user command monitors run after recording.
+	chained []*event.CommandMonitor
+	// inFlight maps the request IDs of the commands whose started event was
+	// recorded in this generation to whether it was redacted. Outcomes are
+	// only recorded for these, so they are filtered and redacted like their
+	// started events (outcomes don't carry the command document), and a Reset
+	// doesn't leave orphaned outcomes.
+	inFlight map[int64]bool
+	// gen counts the Resets.
+	gen uint64
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*recorderOptions)
+
+type recorderOptions struct {
+	cmds       []string
+	t          *testing.T
+	db         string
+	coll       string
+	maxEvents  int
+	tracer     trace.Tracer
+	unredacted bool
+	// legacy keeps the recording contract of the deprecated Monitor; see
+	// withLegacyFilter.
+	legacy bool
+}
+
+// WithCommands limits the recorded command events to the named commands. By
+// default every command is recorded.
+func WithCommands(cmds ...string) RecorderOption {
+	return func(o *recorderOptions) {
+		o.cmds = append(o.cmds, cmds...)
+	}
+}
+
+// WithNamespace limits the recorded command events to commands on database db
+// and, unless coll is empty, collection coll, so tests sharing a server only
+// see their own traffic. The collection is taken from the command document
+// (e.g. {find: "coll"}, or the collection field of getMore); commands that
+// don't name one match only an empty coll.
+func WithNamespace(db, coll string) RecorderOption {
+	return func(o *recorderOptions) {
+		o.db = db
+		o.coll = coll
+	}
+}
+
+// WithLogging logs every recorded event to t.
+func WithLogging(t *testing.T) RecorderOption {
+	return func(o *recorderOptions) {
+		o.t = t
+	}
+}
+
+// WithMaxEvents keeps only the latest n events, so long running tests don't
+// grow the recorder without bound. Older events are dropped and counted; see
+// Recorder.Dropped. By default, or if n <= 0, every event is kept.
+func WithMaxEvents(n int) RecorderOption {
+	return func(o *recorderOptions) {
+		o.maxEvents = n
+	}
+}
+
+// NewRecorder creates a Recorder.
+func NewRecorder(opts ...RecorderOption) *Recorder {
+	r := &Recorder{
+		pool:     mongoevent.NewPoolMonitor(),
+		server:   mongoevent.NewServerMontior(),
+		recorded: make(chan struct{}),
+
+		inFlight: map[int64]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(&r.cfg)
+	}
+
+	poolState := mongoevent.NewPoolEventMonitor(r.pool)
+	serverState := mongoevent.NewEventServerMonitor(r.server)
+
+	recording := &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			if r.wantStarted(evt) {
+				r.record("command started", EventCommandStarted, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			if r.wantFinished(evt.CommandFinishedEvent) && r.record("command succeeded", EventCommandSucceeded, evt) {
+				r.emitSpan(ctx, evt.CommandFinishedEvent, nil)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			if r.wantFinished(evt.CommandFinishedEvent) && r.record("command failed", EventCommandFailed, evt) {
+				r.emitSpan(ctx, evt.CommandFinishedEvent, evt.Failure)
+			}
+		},
+	}
+
+	// Chained monitors run after the event is recorded, so they see it in r.
+	r.commandMonitor = &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, m := range r.commandMonitors(recording) {
+				if m.Started != nil {
+					m.Started(ctx, evt)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, m := range r.commandMonitors(recording) {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, evt)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, m := range r.commandMonitors(recording) {
+				if m.Failed != nil {
+					m.Failed(ctx, evt)
+				}
+			}
+		},
+	}
+
+	r.poolMonitor = &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			poolState.Event(evt)
+
+			switch evt.Type {
+			case event.ConnectionCheckedIn:
+				r.record("connection checked in", EventConnectionCheckedIn, evt)
+			case event.ConnectionCheckedOut:
+				r.record("connection checked out", EventConnectionCheckedOut, evt)
+			case event.ConnectionClosed:
+				r.record("connection closed", EventConnectionClosed, evt)
+			case event.ConnectionPoolCleared:
+				r.record("pool cleared", EventPoolCleared, evt)
+			case event.ConnectionCreated:
+				r.record("connection created", EventConnectionCreated, evt)
+			case event.ConnectionReady:
+				r.record("connection ready", EventConnectionReady, evt)
+			case event.ConnectionCheckOutStarted:
+				r.record("connection check out started", EventConnectionCheckOutStarted, evt)
+			case event.ConnectionCheckOutFailed:
+				r.record("connection check out failed", EventConnectionCheckOutFailed, evt)
+			}
+		},
+	}
+
+	r.serverMonitor = &event.ServerMonitor{
+		TopologyDescriptionChanged: func(evt *event.TopologyDescriptionChangedEvent) {
+			serverState.TopologyDescriptionChanged(evt)
+			r.record("topology description changed", EventTopologyDescriptionChanged, evt)
+		},
+		ServerDescriptionChanged: func(evt *event.ServerDescriptionChangedEvent) {
+			serverState.ServerDescriptionChanged(evt)
+			r.record("server description changed", EventServerDescriptionChanged, evt)
+		},
+		ServerHeartbeatStarted: func(evt *event.ServerHeartbeatStartedEvent) {
+			r.record("server heartbeat started", EventServerHeartbeatStarted, evt)
+		},
+		ServerHeartbeatSucceeded: func(evt *event.ServerHeartbeatSucceededEvent) {
+			serverState.ServerHeartbeatSucceeded(evt)
+			r.record("server heartbeat succeeded", EventServerHeartbeatSucceeded, evt)
+		},
+		ServerHeartbeatFailed: func(evt *event.ServerHeartbeatFailedEvent) {
+			r.record("server heartbeat failed", EventServerHeartbeatFailed, evt)
+		},
+		ServerOpening: func(evt *event.ServerOpeningEvent) {
+			r.record("server opening", EventServerOpening, evt)
+		},
+		ServerClosed: func(evt *event.ServerClosedEvent) {
+			r.record("server closed", EventServerClosed, evt)
+		},
+	}
+
+	return r
+}
+
+// Chain runs m's callbacks for every command event, after r has recorded it,
+// so a test can record events and react to them (e.g. chain a fail point on
+// a failure) through one monitor. m sees every command, regardless of r's
+// filters. Chain returns r and may be called while the client is live.
+func (r *Recorder) Chain(m *event.CommandMonitor) *Recorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.chained = append(r.chained, m)
+
+	return r
+}
+
+// commandMonitors returns recording followed by the chained monitors.
+func (r *Recorder) commandMonitors(recording *event.CommandMonitor) []*event.CommandMonitor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*event.CommandMonitor{recording}, r.chained...)
+}
+
+// CommandMonitor returns the command monitor feeding r.
+func (r *Recorder) CommandMonitor() *event.CommandMonitor {
+	return r.commandMonitor
+}
+
+// PoolMonitor returns the connection pool monitor feeding r.
+func (r *Recorder) PoolMonitor() *event.PoolMonitor {
+	return r.poolMonitor
+}
+
+// ServerMonitor returns the server monitor feeding r.
+func (r *Recorder) ServerMonitor() *event.ServerMonitor {
+	return r.serverMonitor
+}
+
+// ClientOptions returns client options with all of r's monitors set.
+func (r *Recorder) ClientOptions() *options.ClientOptions {
+	return options.Client().
+		SetMonitor(r.commandMonitor).
+		SetPoolMonitor(r.poolMonitor).
+		SetServerMonitor(r.serverMonitor)
+}
+
+func (r *Recorder) wantCommand(name string) bool {
+	return (len(r.cfg.cmds) == 0 && !r.cfg.legacy) || slices.Contains(r.cfg.cmds, name)
+}
+
+func (r *Recorder) filtersNamespace() bool {
+	return r.cfg.db != "" || r.cfg.coll != ""
+}
+
+func (r *Recorder) wantStarted(evt *event.CommandStartedEvent) bool {
+	if !r.wantCommand(evt.CommandName) {
+		return false
+	}
+
+	if !r.filtersNamespace() {
+		return true
+	}
+
+	if r.cfg.db != "" && evt.DatabaseName != r.cfg.db {
+		return false
+	}
+
+	return r.cfg.coll == "" || commandCollection(evt.CommandName, evt.Command) == r.cfg.coll
+}
+
+func (r *Recorder) wantFinished(evt event.CommandFinishedEvent) bool {
+	return r.wantCommand(evt.CommandName)
+}
+
+// finish reports whether the command with requestID was started in this
+// generation and whether it was redacted, and forgets it. r.mu must be held.
+func (r *Recorder) finish(requestID int64) (redacted, ok bool) {
+	redacted, ok = r.inFlight[requestID]
+	delete(r.inFlight, requestID)
+
+	return redacted, ok
+}
+
+// commandCollection returns the collection cmd operates on, or "" if it
+// doesn't name one.
+func commandCollection(name string, cmd bson.Raw) string {
+	if name == "getMore" {
+		coll, _ := cmd.Lookup("collection").StringValueOK()
+		return coll
+	}
+
+	elems, err := cmd.Elements()
+	if err != nil || len(elems) == 0 {
+		return ""
+	}
+
+	// Collection commands name the collection as their first value, e.g.
+	// {find: "coll"}; database commands have a number there.
+	coll, _ := elems[0].Value().StringValueOK()
+
+	return coll
+}
+
+func (r *Recorder) logf(format string, args ...any) {
+	if r.cfg.t != nil {
+		r.cfg.t.Logf(format, args...)
+	}
+}
+
+// record records evt, redacted if r redacts it, and reports whether it did;
+// outcomes of commands whose started event wasn't recorded are dropped.
+func (r *Recorder) record(desc string, typ EventType, evt any) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch cmd := evt.(type) {
+	case *event.CommandStartedEvent:
+		redact := r.redacts(cmd)
+		if redact {
+			evt = redactStarted(cmd)
+		}
+
+		r.inFlight[cmd.RequestID] = redact
+	case *event.CommandSucceededEvent:
+		redacted, ok := r.finish(cmd.RequestID)
+		if !ok {
+			return false
+		}
+
+		if redacted {
+			evt = redactSucceeded(cmd)
+		}
+	case *event.CommandFailedEvent:
+		if _, ok := r.finish(cmd.RequestID); !ok {
+			return false
+		}
+	}
+
+	if r.cfg.legacy && !legacyEvents[typ] {
+		// Monitor logged command successes without recording them.
+		if typ == EventCommandSucceeded {
+			r.logf("%s: %+v", desc, evt)
+		}
+
+		return false
+	}
+
+	r.logf("%s: %+v", desc, evt)
+
+	e := RecordedEvent{Type: typ, Event: evt, Time: time.Now()}
+
+	if n := r.cfg.maxEvents; n > 0 && len(r.events) == n {
+		r.events[r.head] = e
+		r.head = (r.head + 1) % n
+		r.dropped++
+	} else {
+		r.events = append(r.events, e)
+	}
+
+	close(r.recorded)
+	r.recorded = make(chan struct{})
+
+	return true
+}
+
+// Reset discards the recorded events and starts a new generation. It is safe
+// to call while the client is live: outcomes of commands started before the
+// Reset are dropped rather than recorded without their started event.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = nil
+	r.head, r.dropped = 0, 0
+	clear(r.inFlight)
+	r.gen++
+}
+
+// Dropped returns the number of events dropped since the last Reset because
+// r held WithMaxEvents events.
+func (r *Recorder) Dropped() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.dropped
+}
+
+// ordered returns the recorded events, oldest first. r.mu must be held, and
+// the result must not be modified.
+func (r *Recorder) ordered() []RecordedEvent {
+	if r.head == 0 {
+		return r.events
+	}
+
+	return append(slices.Clone(r.events[r.head:]), r.events[:r.head]...)
+}
+
+// Generation returns the number of times r has been Reset.
+func (r *Recorder) Generation() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.gen
+}
+
+// WaitForEvent blocks until an event matching predicate has been recorded
+// and returns the first such event. Events recorded before the call count,
+// so there is no race between triggering an event and waiting for it. It
+// returns ctx's error if ctx is done first. predicate runs with r locked, so
+// it must not call r's methods.
+func (r *Recorder) WaitForEvent(ctx context.Context, predicate func(RecordedEvent) bool) (RecordedEvent, error) {
+	// next is the position of the next event to check, counting the dropped
+	// events, so it stays put as the ring buffer wraps.
+	next := 0
+
+	r.mu.Lock()
+	gen := r.gen
+	r.mu.Unlock()
+
+	for {
+		r.mu.Lock()
+
+		// Reset discards events; start over.
+		if r.gen != gen {
+			gen, next = r.gen, 0
+		}
+
+		// Events dropped before they were checked are missed.
+		next = max(next, r.dropped)
+
+		events := r.ordered()
+		for ; next < r.dropped+len(events); next++ {
+			if e := events[next-r.dropped]; predicate(e) {
+				r.mu.Unlock()
+				return e, nil
+			}
+		}
+
+		recorded := r.recorded
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return RecordedEvent{}, fmt.Errorf("wait for event: %w", ctx.Err())
+		case <-recorded:
+		}
+	}
+}
+
+// Events returns a copy of all recorded events in order.
+func (r *Recorder) Events() []RecordedEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]RecordedEvent(nil), r.ordered()...)
+}
+
+// eventsOf returns the recorded events of type typ in order.
+func eventsOf[T any](r *Recorder, typ EventType) []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []T
+	for _, e := range r.ordered() {
+		if e.Type == typ {
+			events = append(events, e.Event.(T))
+		}
+	}
+
+	return events
+}
+
+// CommandStartedEvents returns all command started events in order.
+func (r *Recorder) CommandStartedEvents() []*event.CommandStartedEvent {
+	return eventsOf[*event.CommandStartedEvent](r, EventCommandStarted)
+}
+
+// CommandSucceededEvents returns all command succeeded events, including
+// the server replies, in order.
+func (r *Recorder) CommandSucceededEvents() []*event.CommandSucceededEvent {
+	return eventsOf[*event.CommandSucceededEvent](r, EventCommandSucceeded)
+}
+
+// CommandFailedEvents returns all command failed events in order.
+func (r *Recorder) CommandFailedEvents() []*event.CommandFailedEvent {
+	return eventsOf[*event.CommandFailedEvent](r, EventCommandFailed)
+}
+
+// ConnectionCheckedOutEvents returns all connection checked out events in order.
+func (r *Recorder) ConnectionCheckedOutEvents() []*event.PoolEvent {
+	return eventsOf[*event.PoolEvent](r, EventConnectionCheckedOut)
+}
+
+// ConnectionCheckedInEvents returns all connection checked in events in order.
+func (r *Recorder) ConnectionCheckedInEvents() []*event.PoolEvent {
+	return eventsOf[*event.PoolEvent](r, EventConnectionCheckedIn)
+}
+
+// PoolClearedEvents returns all pool cleared events in order.
+func (r *Recorder) PoolClearedEvents() []*event.PoolEvent {
+	return eventsOf[*event.PoolEvent](r, EventPoolCleared)
+}
+
+// ConnectionClosedEvents returns all connection closed events in order.
+func (r *Recorder) ConnectionClosedEvents() []*event.PoolEvent {
+	return eventsOf[*event.PoolEvent](r, EventConnectionClosed)
+}
+
+// ConnectionCreatedEvents returns all connection created events in order.
+func (r *Recorder) ConnectionCreatedEvents() []*event.PoolEvent {
+	return eventsOf[*event.PoolEvent](r, EventConnectionCreated)
+}
+
+// ConnectionReadyEvents returns all connection ready events in order.
+func (r *Recorder) ConnectionReadyEvents() []*event.PoolEvent {
+	return eventsOf[*event.PoolEvent](r, EventConnectionReady)
+}
+
+// ConnectionCheckOutStartedEvents returns all connection check out started
+// events in order.
+func (r *Recorder) ConnectionCheckOutStartedEvents() []*event.PoolEvent {
+	return eventsOf[*event.PoolEvent](r, EventConnectionCheckOutStarted)
+}
+
+// ConnectionCheckOutFailedEvents returns all connection check out failed
+// events in order. Their Reason says why, e.g. event.ReasonTimedOut.
+func (r *Recorder) ConnectionCheckOutFailedEvents() []*event.PoolEvent {
+	return eventsOf[*event.PoolEvent](r, EventConnectionCheckOutFailed)
+}
+
+// TopologyDescriptionChangedEvents returns all topology description changed
+// events in order.
+func (r *Recorder) TopologyDescriptionChangedEvents() []*event.TopologyDescriptionChangedEvent {
+	return eventsOf[*event.TopologyDescriptionChangedEvent](r, EventTopologyDescriptionChanged)
+}
+
+// ServerDescriptionChangedEvents returns all server description changed
+// events in order.
+func (r *Recorder) ServerDescriptionChangedEvents() []*event.ServerDescriptionChangedEvent {
+	return eventsOf[*event.ServerDescriptionChangedEvent](r, EventServerDescriptionChanged)
+}
+
+// ServerHeartbeatStartedEvents returns all server heartbeat started events
+// in order.
+func (r *Recorder) ServerHeartbeatStartedEvents() []*event.ServerHeartbeatStartedEvent {
+	return eventsOf[*event.ServerHeartbeatStartedEvent](r, EventServerHeartbeatStarted)
+}
+
+// ServerHeartbeatSucceededEvents returns all server heartbeat succeeded
+// events in order.
+func (r *Recorder) ServerHeartbeatSucceededEvents() []*event.ServerHeartbeatSucceededEvent {
+	return eventsOf[*event.ServerHeartbeatSucceededEvent](r, EventServerHeartbeatSucceeded)
+}
+
+// ServerHeartbeatFailedEvents returns all server heartbeat failed events in
+// order.
+func (r *Recorder) ServerHeartbeatFailedEvents() []*event.ServerHeartbeatFailedEvent {
+	return eventsOf[*event.ServerHeartbeatFailedEvent](r, EventServerHeartbeatFailed)
+}
+
+// ServerOpeningEvents returns all server opening events in order.
+func (r *Recorder) ServerOpeningEvents() []*event.ServerOpeningEvent {
+	return eventsOf[*event.ServerOpeningEvent](r, EventServerOpening)
+}
+
+// ServerClosedEvents returns all server closed events in order.
+func (r *Recorder) ServerClosedEvents() []*event.ServerClosedEvent {
+	return eventsOf[*event.ServerClosedEvent](r, EventServerClosed)
+}
+
+// EventsOfType returns the recorded events of type typ, with their
+// timestamps, in order. Use it to assert on the timing of events, e.g. the
+// interval between heartbeats.
+func (r *Recorder) EventsOfType(typ EventType) []RecordedEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []RecordedEvent
+	for _, e := range r.ordered() {
+		if e.Type == typ {
+			events = append(events, e)
+		}
+	}
+
+	return events
+}
+
+// ConnsReady returns the number of ready connections for the given server
+// address.
+func (r *Recorder) ConnsReady(serverAddr string) int {
+	return r.pool.ConnsReady(serverAddr)
+}
+
+// PoolCounts returns the pool event counts for the given server address.
+func (r *Recorder) PoolCounts(serverAddr string) mongoevent.PoolCounts {
+	return r.pool.Counts(serverAddr)
+}
+
+// PoolClears returns the pool clears, with their generations, for the given
+// server address.
+func (r *Recorder) PoolClears(serverAddr string) []mongoevent.PoolClear {
+	return r.pool.Clears(serverAddr)
+}
+
+// LatestTopologyDescription returns the topology description of the most
+// recent topology description changed event.
+func (r *Recorder) LatestTopologyDescription() event.TopologyDescription {
+	return r.server.LatestTopologyDescription()
+}
+
+// RTT returns the moving average heartbeat round trip time to the given
+// server address.
+func (r *Recorder) RTT(serverAddr string) time.Duration {
+	return r.server.RTT(serverAddr)
+}
+
+// RTT90 returns the 90th percentile of the recent heartbeat round trip times
+// to the given server address.
+func (r *Recorder) RTT90(serverAddr string) time.Duration {
+	return r.server.RTT90(serverAddr)
+}
+
+// Pool returns the pool state r maintains.
+func (r *Recorder) Pool() *mongoevent.PoolMonitor {
+	return r.pool
+}
+
+// Server returns the server state r maintains.
+func (r *Recorder) Server() *mongoevent.ServerMonitor {
+	return r.server
+}
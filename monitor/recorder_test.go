@@ -0,0 +1,310 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo/address"
+	"go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore"
+)
+
+func startFind(r *Recorder, requestID int64) {
+	r.CommandMonitor().Started(context.Background(), &event.CommandStartedEvent{
+		CommandName: "find",
+		RequestID:   requestID,
+	})
+}
+
+func succeedFind(r *Recorder, requestID int64) {
+	r.CommandMonitor().Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: requestID},
+	})
+}
+
+func TestRecorderResetWhileRecording(t *testing.T) {
+	r := NewRecorder()
+
+	var wg sync.WaitGroup
+
+	for g := range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range 200 {
+				id := int64(g*1000 + i)
+
+				startFind(r, id)
+				succeedFind(r, id)
+			}
+		}()
+	}
+
+	for range 50 {
+		r.Reset()
+		_ = r.Events()
+	}
+
+	wg.Wait()
+
+	assert.EqualValues(t, 50, r.Generation())
+
+	// Every recorded outcome has its started event in the same generation.
+	started := map[int64]bool{}
+	for _, evt := range r.CommandStartedEvents() {
+		started[evt.RequestID] = true
+	}
+
+	for _, evt := range r.CommandSucceededEvents() {
+		assert.True(t, started[evt.RequestID], "outcome of request %d recorded without its started event", evt.RequestID)
+	}
+}
+
+func TestRecorderResetDropsOrphanedOutcomes(t *testing.T) {
+	r := NewRecorder()
+
+	startFind(r, 1)
+	r.Reset()
+	succeedFind(r, 1)
+
+	assert.Empty(t, r.Events())
+
+	startFind(r, 2)
+	succeedFind(r, 2)
+
+	ex, ok := r.ExchangeFor(2)
+	require.True(t, ok)
+	assert.NotNil(t, ex.Succeeded)
+}
+
+func TestRecorderWaitForEventAcrossReset(t *testing.T) {
+	r := NewRecorder()
+
+	startFind(r, 1)
+	startFind(r, 2)
+	r.Reset()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		startFind(r, 3)
+	}()
+
+	got, err := r.WaitForEvent(ctx, func(e RecordedEvent) bool {
+		return e.Type == EventCommandStarted
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, got.Event.(*event.CommandStartedEvent).RequestID)
+}
+
+func TestRecorderWaitForEventContextDone(t *testing.T) {
+	r := NewRecorder()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := r.WaitForEvent(ctx, func(RecordedEvent) bool { return true })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRecorderMaxEvents(t *testing.T) {
+	r := NewRecorder(WithMaxEvents(3))
+
+	for id := range int64(5) {
+		startFind(r, id)
+	}
+
+	var ids []int64
+	for _, evt := range r.CommandStartedEvents() {
+		ids = append(ids, evt.RequestID)
+	}
+
+	assert.Equal(t, []int64{2, 3, 4}, ids)
+	assert.Equal(t, 2, r.Dropped())
+
+	// Positions count the dropped events, so waiting still finds the
+	// retained ones.
+	got, err := r.WaitForEvent(context.Background(), func(e RecordedEvent) bool {
+		return e.Event.(*event.CommandStartedEvent).RequestID == 3
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, got.Event.(*event.CommandStartedEvent).RequestID)
+
+	r.Reset()
+
+	assert.Zero(t, r.Dropped())
+	assert.Empty(t, r.Events())
+}
+
+func TestRecorderRedactsSensitiveCommands(t *testing.T) {
+	hello := bson.Raw(bsoncore.NewDocumentBuilder().AppendInt32("hello", 1).Build())
+	speculative := bson.Raw(bsoncore.NewDocumentBuilder().
+		AppendInt32("hello", 1).
+		AppendDocument("speculativeAuthenticate", bsoncore.NewDocumentBuilder().AppendInt32("saslStart", 1).Build()).
+		Build())
+	isMaster := bson.Raw(bsoncore.NewDocumentBuilder().
+		AppendInt32("isMaster", 1).
+		AppendDocument("speculativeAuthenticate", bsoncore.NewDocumentBuilder().AppendInt32("saslStart", 1).Build()).
+		Build())
+	saslStart := bson.Raw(bsoncore.NewDocumentBuilder().AppendInt32("saslStart", 1).Build())
+
+	for _, tc := range []struct {
+		name     string
+		cmdName  string
+		cmd      bson.Raw
+		opts     []RecorderOption
+		redacted bool
+	}{
+		{name: "hello", cmdName: "hello", cmd: hello},
+		{name: "speculative hello", cmdName: "hello", cmd: speculative, redacted: true},
+		{name: "speculative isMaster", cmdName: "isMaster", cmd: isMaster, redacted: true},
+		{name: "sensitive command", cmdName: "saslStart", cmd: saslStart, redacted: true},
+		{name: "unredacted", cmdName: "hello", cmd: speculative, opts: []RecorderOption{WithUnredacted()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var chained *event.CommandStartedEvent
+
+			r := NewRecorder(tc.opts...).Chain(&event.CommandMonitor{
+				Started: func(_ context.Context, evt *event.CommandStartedEvent) { chained = evt },
+			})
+
+			r.CommandMonitor().Started(context.Background(), &event.CommandStartedEvent{CommandName: tc.cmdName, RequestID: 1, Command: tc.cmd})
+			r.CommandMonitor().Succeeded(context.Background(), &event.CommandSucceededEvent{
+				CommandFinishedEvent: event.CommandFinishedEvent{CommandName: tc.cmdName, RequestID: 1},
+				Reply:                tc.cmd,
+			})
+
+			started := r.CommandStartedEvents()
+			succeeded := r.CommandSucceededEvents()
+			require.Len(t, started, 1)
+			require.Len(t, succeeded, 1)
+
+			if tc.redacted {
+				assert.Empty(t, started[0].Command)
+				assert.Empty(t, succeeded[0].Reply)
+			} else {
+				assert.Equal(t, tc.cmd, started[0].Command)
+				assert.Equal(t, tc.cmd, succeeded[0].Reply)
+			}
+
+			// Chained monitors see the event as published.
+			require.NotNil(t, chained)
+			assert.Equal(t, tc.cmd, chained.Command)
+		})
+	}
+}
+
+func TestRecorderNamespaceFilter(t *testing.T) {
+	doc := func(build func(*bsoncore.DocumentBuilder) *bsoncore.DocumentBuilder) bson.Raw {
+		return bson.Raw(build(bsoncore.NewDocumentBuilder()).Build())
+	}
+
+	find := func(coll string) bson.Raw {
+		return doc(func(b *bsoncore.DocumentBuilder) *bsoncore.DocumentBuilder {
+			return b.AppendString("find", coll).AppendDocument("filter", bsoncore.NewDocumentBuilder().Build())
+		})
+	}
+
+	getMore := func(coll string) bson.Raw {
+		return doc(func(b *bsoncore.DocumentBuilder) *bsoncore.DocumentBuilder {
+			return b.AppendInt64("getMore", 42).AppendString("collection", coll)
+		})
+	}
+
+	ping := doc(func(b *bsoncore.DocumentBuilder) *bsoncore.DocumentBuilder {
+		return b.AppendInt32("ping", 1)
+	})
+
+	for _, tc := range []struct {
+		name     string
+		db, coll string // WithNamespace arguments
+		cmdName  string
+		cmdDB    string
+		cmd      bson.Raw
+		want     bool
+	}{
+		{name: "collection in first element", db: "db", coll: "coll", cmdName: "find", cmdDB: "db", cmd: find("coll"), want: true},
+		{name: "other collection", db: "db", coll: "coll", cmdName: "find", cmdDB: "db", cmd: find("other")},
+		{name: "other database", db: "db", coll: "coll", cmdName: "find", cmdDB: "other", cmd: find("coll")},
+		{name: "getMore collection field", db: "db", coll: "coll", cmdName: "getMore", cmdDB: "db", cmd: getMore("coll"), want: true},
+		{name: "getMore other collection", db: "db", coll: "coll", cmdName: "getMore", cmdDB: "db", cmd: getMore("other")},
+		{name: "numeric first value with collection", db: "db", coll: "coll", cmdName: "ping", cmdDB: "db", cmd: ping},
+		{name: "numeric first value database only", db: "db", cmdName: "ping", cmdDB: "db", cmd: ping, want: true},
+		{name: "collection only", coll: "coll", cmdName: "find", cmdDB: "any", cmd: find("coll"), want: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRecorder(WithNamespace(tc.db, tc.coll))
+
+			r.CommandMonitor().Started(context.Background(), &event.CommandStartedEvent{
+				CommandName:  tc.cmdName,
+				DatabaseName: tc.cmdDB,
+				RequestID:    1,
+				Command:      tc.cmd,
+			})
+
+			// Outcomes don't carry the command; they follow their started
+			// event through inFlight.
+			r.CommandMonitor().Succeeded(context.Background(), &event.CommandSucceededEvent{
+				CommandFinishedEvent: event.CommandFinishedEvent{CommandName: tc.cmdName, DatabaseName: tc.cmdDB, RequestID: 1},
+			})
+			r.CommandMonitor().Failed(context.Background(), &event.CommandFailedEvent{
+				CommandFinishedEvent: event.CommandFinishedEvent{CommandName: tc.cmdName, DatabaseName: tc.cmdDB, RequestID: 2},
+			})
+
+			want := 0
+			if tc.want {
+				want = 1
+			}
+
+			assert.Len(t, r.CommandStartedEvents(), want)
+			assert.Len(t, r.CommandSucceededEvents(), want)
+			assert.Empty(t, r.CommandFailedEvents(), "outcome without a started event")
+		})
+	}
+}
+
+func TestRecorderServerTimeline(t *testing.T) {
+	r := NewRecorder()
+
+	changeKind := func(kind string) {
+		r.ServerMonitor().ServerDescriptionChanged(&event.ServerDescriptionChangedEvent{
+			Address:        address.Address("localhost:27017"),
+			NewDescription: event.ServerDescription{Kind: kind},
+		})
+	}
+
+	changeKind("RSSecondary")
+	between := time.Now()
+	time.Sleep(time.Millisecond)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		changeKind("RSPrimary")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	desc, err := r.Server().WaitForServerKind(ctx, "localhost:27017", "RSPrimary")
+	require.NoError(t, err)
+	assert.Equal(t, "RSPrimary", desc.Kind)
+
+	before, ok := r.Server().ServerStateAt("localhost:27017", between)
+	require.True(t, ok)
+	assert.Equal(t, "RSSecondary", before.Kind)
+
+	_, ok = r.Server().ServerStateAt("localhost:27017", time.Time{})
+	assert.False(t, ok)
+
+	assert.Len(t, r.Server().ServerTimeline("localhost:27017"), 2)
+	assert.Len(t, r.ServerDescriptionChangedEvents(), 2)
+}
@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// sensitiveCommands are the commands whose payloads the driver never
+// publishes, as they carry credentials.
+var sensitiveCommands = map[string]bool{
+	"authenticate":    true,
+	"saslStart":       true,
+	"saslContinue":    true,
+	"getnonce":        true,
+	"createUser":      true,
+	"updateUser":      true,
+	"copydbgetnonce":  true,
+	"copydbsaslstart": true,
+	"copydb":          true,
+}
+
+// WithUnredacted records the payloads of sensitive commands as the driver
+// publishes them, for local debugging. The driver itself still redacts the
+// authentication commands, and hellos carrying speculative authentication.
+func WithUnredacted() RecorderOption {
+	return func(o *recorderOptions) {
+		o.unredacted = true
+	}
+}
+
+// redacts reports whether r records the command started by evt, and its
+// outcome, with redacted payloads: by default, the commands the driver
+// redacts, i.e. the sensitive commands and hellos (or isMasters) carrying
+// speculative authentication.
+func (r *Recorder) redacts(evt *event.CommandStartedEvent) bool {
+	if r.cfg.unredacted {
+		return false
+	}
+
+	if sensitiveCommands[evt.CommandName] {
+		return true
+	}
+
+	if evt.CommandName == "hello" || strings.EqualFold(evt.CommandName, "isMaster") {
+		_, err := evt.Command.LookupErr("speculativeAuthenticate")
+		return err == nil
+	}
+
+	return false
+}
+
+// The redact functions return a copy of evt with its payload emptied, like
+// the driver's. evt itself is shared with the driver and chained monitors, so
+// it isn't modified.
+
+func redactStarted(evt *event.CommandStartedEvent) *event.CommandStartedEvent {
+	redacted := *evt
+	redacted.Command = bson.Raw{}
+
+	return &redacted
+}
+
+func redactSucceeded(evt *event.CommandSucceededEvent) *event.CommandSucceededEvent {
+	redacted := *evt
+	redacted.Reply = bson.Raw{}
+
+	return &redacted
+}
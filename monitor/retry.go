@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Attempts returns the number of times each logical cmdName operation was
+// started, in the order the operations started. Attempts belong to the same
+// operation if they carry the same session (lsid) and txnNumber, as retried
+// writes do. Retried reads carry no txnNumber, so an attempt on the session
+// of a failed attempt is taken as its retry, while one following a success
+// starts a new operation. Commands without a session are operations of their
+// own.
+func (r *Recorder) Attempts(cmdName string) []int {
+	type opKey struct {
+		lsid      string
+		txnNumber int64
+		hasTxn    bool
+	}
+
+	var attempts []int
+
+	// last maps an operation key to its index in attempts and whether its
+	// latest attempt failed.
+	type opState struct {
+		index  int
+		failed bool
+	}
+
+	last := map[opKey]*opState{}
+
+	for _, ex := range r.Correlate() {
+		if ex.Started.CommandName != cmdName {
+			continue
+		}
+
+		cmd := ex.Started.Command
+
+		lsid, err := cmd.LookupErr("lsid")
+		if err != nil {
+			attempts = append(attempts, 1)
+			continue
+		}
+
+		key := opKey{lsid: string(lsid.Value)}
+		key.txnNumber, key.hasTxn = cmd.Lookup("txnNumber").AsInt64OK()
+
+		op, ok := last[key]
+		if !ok || (!key.hasTxn && !op.failed) {
+			op = &opState{index: len(attempts)}
+			last[key] = op
+			attempts = append(attempts, 0)
+		}
+
+		attempts[op.index]++
+		op.failed = ex.Failed != nil
+	}
+
+	return attempts
+}
+
+// AssertRetried fails the test unless the last logical cmdName operation was
+// started expectedAttempts times, i.e. retried expectedAttempts-1 times. See
+// Attempts for how attempts are grouped into operations.
+func (r *Recorder) AssertRetried(t *testing.T, cmdName string, expectedAttempts int) {
+	t.Helper()
+
+	attempts := r.Attempts(cmdName)
+	require.NotEmpty(t, attempts, "expected a %q command to be started", cmdName)
+
+	got := attempts[len(attempts)-1]
+	require.Equal(t, expectedAttempts, got, "expected the last %q operation to be attempted %d times, got %d (attempts per operation: %v)",
+		cmdName, expectedAttempts, got, attempts)
+}
@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func TestRecorderAttempts(t *testing.T) {
+	r := NewRecorder()
+	cm := r.CommandMonitor()
+	ctx := context.Background()
+
+	session := bson.D{{Key: "id", Value: bson.Binary{Subtype: 4, Data: []byte("0123456789abcdef")}}}
+
+	requestID := int64(0)
+	attempt := func(cmdName string, cmd bson.D, fail bool) {
+		requestID++
+
+		cm.Started(ctx, &event.CommandStartedEvent{CommandName: cmdName, RequestID: requestID, Command: mustMarshal(t, cmd)})
+
+		finished := event.CommandFinishedEvent{CommandName: cmdName, RequestID: requestID}
+		if fail {
+			cm.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: errors.New("retryable")})
+		} else {
+			cm.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+		}
+	}
+
+	insert := func(txnNumber int64) bson.D {
+		return bson.D{{Key: "insert", Value: "coll"}, {Key: "lsid", Value: session}, {Key: "txnNumber", Value: txnNumber}}
+	}
+
+	find := bson.D{{Key: "find", Value: "coll"}, {Key: "lsid", Value: session}}
+
+	// Writes are grouped by txnNumber.
+	attempt("insert", insert(1), false)
+	attempt("insert", insert(2), true)
+	attempt("insert", insert(2), false)
+
+	// Reads on the same session are retries only after a failure.
+	attempt("find", find, false)
+	attempt("find", find, true)
+	attempt("find", find, true)
+	attempt("find", find, false)
+
+	// Commands without a session are separate operations.
+	attempt("ping", bson.D{{Key: "ping", Value: 1}}, true)
+	attempt("ping", bson.D{{Key: "ping", Value: 1}}, true)
+
+	assert.Equal(t, []int{1, 2}, r.Attempts("insert"))
+	assert.Equal(t, []int{1, 3}, r.Attempts("find"))
+	assert.Equal(t, []int{1, 1}, r.Attempts("ping"))
+
+	r.AssertRetried(t, "insert", 2)
+	r.AssertRetried(t, "find", 3)
+}
@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// DurationStats summarizes a set of durations.
+type DurationStats struct {
+	Count int
+	Min   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// newDurationStats summarizes durations. Percentiles use the nearest-rank
+// method.
+func newDurationStats(durations []time.Duration) DurationStats {
+	if len(durations) == 0 {
+		return DurationStats{}
+	}
+
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+
+	return DurationStats{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Mean:  sum / time.Duration(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank pth percentile of sorted.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+
+	return sorted[max(rank, 1)-1]
+}
+
+// CheckOutStats summarizes how long successful connection check-outs from
+// the given server's pool waited, from the Duration of each checked out
+// event (measured from check-out start).
+func (r *Recorder) CheckOutStats(serverAddr string) DurationStats {
+	var durations []time.Duration
+	for _, evt := range r.ConnectionCheckedOutEvents() {
+		if evt.Address == serverAddr {
+			durations = append(durations, evt.Duration)
+		}
+	}
+
+	return newDurationStats(durations)
+}
+
+// Stats summarizes the durations of the recorded cmdName commands that
+// finished, successfully or not, as reported by their succeeded and failed
+// events.
+func (r *Recorder) Stats(cmdName string) DurationStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var durations []time.Duration
+	for _, e := range r.ordered() {
+		switch evt := e.Event.(type) {
+		case *event.CommandSucceededEvent:
+			if evt.CommandName == cmdName {
+				durations = append(durations, evt.Duration)
+			}
+		case *event.CommandFailedEvent:
+			if evt.CommandName == cmdName {
+				durations = append(durations, evt.Duration)
+			}
+		}
+	}
+
+	return newDurationStats(durations)
+}
@@ -0,0 +1,40 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDurationStats(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, DurationStats{}, newDurationStats(nil))
+	})
+
+	t.Run("nearest rank", func(t *testing.T) {
+		var durations []time.Duration
+		for i := 100; i >= 1; i-- {
+			durations = append(durations, time.Duration(i)*time.Millisecond)
+		}
+
+		got := newDurationStats(durations)
+
+		assert.Equal(t, DurationStats{
+			Count: 100,
+			Min:   time.Millisecond,
+			Mean:  50500 * time.Microsecond,
+			P50:   50 * time.Millisecond,
+			P95:   95 * time.Millisecond,
+			P99:   99 * time.Millisecond,
+			Max:   100 * time.Millisecond,
+		}, got)
+	})
+
+	t.Run("single", func(t *testing.T) {
+		got := newDurationStats([]time.Duration{time.Second})
+
+		assert.Equal(t, time.Second, got.P50)
+		assert.Equal(t, time.Second, got.P99)
+	})
+}
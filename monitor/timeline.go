@@ -0,0 +1,178 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// timelineSpan is an interval on the rendered timeline.
+type timelineSpan struct {
+	name       string
+	tag        string // mermaid task tag: "", "crit", or "active"
+	start, end time.Time
+}
+
+// RenderTimeline writes r's recorded commands, connection check outs, and
+// heartbeats to w as a mermaid Gantt chart, one section each, so overlapping
+// events (e.g. a command racing a heartbeat timeout) can be seen at a glance.
+// Paste the output into any mermaid renderer. Failed commands and heartbeats
+// are marked critical; spans that hadn't finished when the last event was
+// recorded are marked active and end there. Times have millisecond
+// precision, so shorter spans are drawn 1ms long.
+func (r *Recorder) RenderTimeline(w io.Writer) error {
+	commands, checkOuts, heartbeats := r.timelineSpans()
+
+	var b strings.Builder
+
+	b.WriteString("gantt\n")
+	b.WriteString("    title Recorded events\n")
+	b.WriteString("    dateFormat x\n")
+	b.WriteString("    axisFormat %H:%M:%S.%L\n")
+
+	for _, section := range []struct {
+		name  string
+		spans []timelineSpan
+	}{
+		{"Commands", commands},
+		{"Connection check outs", checkOuts},
+		{"Heartbeats", heartbeats},
+	} {
+		if len(section.spans) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "    section %s\n", section.name)
+
+		for _, s := range section.spans {
+			end := s.end
+			if !end.After(s.start) {
+				end = s.start.Add(time.Millisecond)
+			}
+
+			tag := ""
+			if s.tag != "" {
+				tag = s.tag + ", "
+			}
+
+			fmt.Fprintf(&b, "    %s :%s%d, %d\n", timelineName(s.name), tag, s.start.UnixMilli(), end.UnixMilli())
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write timeline: %w", err)
+	}
+
+	return nil
+}
+
+// timelineSpans pairs r's recorded events into command, check out, and
+// heartbeat spans, each in the order they started.
+func (r *Recorder) timelineSpans() (commands, checkOuts, heartbeats []timelineSpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := r.ordered()
+	if len(events) == 0 {
+		return nil, nil, nil
+	}
+
+	type commandKey struct {
+		requestID    int64
+		connectionID string
+	}
+
+	type connKey struct {
+		addr string
+		id   int64
+	}
+
+	// The open maps hold the indexes of the spans that haven't ended.
+	openCommands := map[commandKey]int{}
+	openCheckOuts := map[connKey]int{}
+	openHeartbeats := map[string]int{}
+
+	end := func(spans []timelineSpan, i int, at time.Time, tag string) {
+		spans[i].end = at
+		spans[i].tag = tag
+	}
+
+	for _, e := range events {
+		switch evt := e.Event.(type) {
+		case *event.CommandStartedEvent:
+			openCommands[commandKey{evt.RequestID, evt.ConnectionID}] = len(commands)
+			commands = append(commands, timelineSpan{
+				name:  fmt.Sprintf("%s (request %d)", evt.CommandName, evt.RequestID),
+				tag:   "active",
+				start: e.Time,
+			})
+		case *event.CommandSucceededEvent:
+			key := commandKey{evt.RequestID, evt.ConnectionID}
+			if i, ok := openCommands[key]; ok {
+				end(commands, i, e.Time, "")
+				delete(openCommands, key)
+			}
+		case *event.CommandFailedEvent:
+			key := commandKey{evt.RequestID, evt.ConnectionID}
+			if i, ok := openCommands[key]; ok {
+				end(commands, i, e.Time, "crit")
+				delete(openCommands, key)
+			}
+		case *event.PoolEvent:
+			key := connKey{evt.Address, evt.ConnectionID}
+
+			switch evt.Type {
+			case event.ConnectionCheckedOut:
+				openCheckOuts[key] = len(checkOuts)
+				checkOuts = append(checkOuts, timelineSpan{
+					name:  fmt.Sprintf("conn %d %s", evt.ConnectionID, evt.Address),
+					tag:   "active",
+					start: e.Time,
+				})
+			case event.ConnectionCheckedIn:
+				if i, ok := openCheckOuts[key]; ok {
+					end(checkOuts, i, e.Time, "")
+					delete(openCheckOuts, key)
+				}
+			}
+		case *event.ServerHeartbeatStartedEvent:
+			openHeartbeats[evt.ConnectionID] = len(heartbeats)
+			heartbeats = append(heartbeats, timelineSpan{
+				name:  evt.ConnectionID,
+				tag:   "active",
+				start: e.Time,
+			})
+		case *event.ServerHeartbeatSucceededEvent:
+			if i, ok := openHeartbeats[evt.ConnectionID]; ok {
+				end(heartbeats, i, e.Time, "")
+				delete(openHeartbeats, evt.ConnectionID)
+			}
+		case *event.ServerHeartbeatFailedEvent:
+			if i, ok := openHeartbeats[evt.ConnectionID]; ok {
+				end(heartbeats, i, e.Time, "crit")
+				delete(openHeartbeats, evt.ConnectionID)
+			}
+		}
+	}
+
+	// Spans still open end with the recording.
+	last := events[len(events)-1].Time
+	for _, spans := range [][]timelineSpan{commands, checkOuts, heartbeats} {
+		for i := range spans {
+			if spans[i].tag == "active" {
+				spans[i].end = last
+			}
+		}
+	}
+
+	return commands, checkOuts, heartbeats
+}
+
+// timelineName makes name safe for a mermaid task name, in which ':' and
+// '#' and ';' are syntax.
+func timelineName(name string) string {
+	return strings.NewReplacer(":", " ", "#", "", ";", " ").Replace(name)
+}
@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func TestRecorderRenderTimeline(t *testing.T) {
+	r := NewRecorder()
+	ctx := context.Background()
+
+	startFind(r, 1)
+	r.PoolMonitor().Event(&event.PoolEvent{Type: event.ConnectionCheckedOut, Address: "localhost:27017", ConnectionID: 7})
+	r.ServerMonitor().ServerHeartbeatStarted(&event.ServerHeartbeatStartedEvent{ConnectionID: "localhost:27017[-2]"})
+	r.CommandMonitor().Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1},
+		Failure:              errors.New("timeout"),
+	})
+	r.PoolMonitor().Event(&event.PoolEvent{Type: event.ConnectionCheckedIn, Address: "localhost:27017", ConnectionID: 7})
+	startFind(r, 2)
+
+	var b strings.Builder
+	require.NoError(t, r.RenderTimeline(&b))
+
+	out := b.String()
+	require.True(t, strings.HasPrefix(out, "gantt\n"), out)
+
+	// Task lines are "<name> :[tag, ]<start>, <end>".
+	var tasks []string
+	for _, line := range strings.Split(out, "\n") {
+		name, rest, ok := strings.Cut(strings.TrimSpace(line), " :")
+		if !ok {
+			continue
+		}
+
+		tag := ""
+		if first, _, _ := strings.Cut(rest, ","); first == "crit" || first == "active" {
+			tag = " [" + first + "]"
+		}
+
+		tasks = append(tasks, name+tag)
+	}
+
+	assert.Equal(t, []string{
+		"find (request 1) [crit]",
+		"find (request 2) [active]",
+		"conn 7 localhost 27017",
+		"localhost 27017[-2] [active]",
+	}, tasks)
+
+	assert.Contains(t, out, "section Commands\n")
+	assert.Contains(t, out, "section Connection check outs\n")
+	assert.Contains(t, out, "section Heartbeats\n")
+}